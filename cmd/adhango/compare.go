@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	timetable "github.com/mnadev/adhango/pkg/timetable"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// compare reads a timetable and reports the days that differ from the calculated prayer times.
+func compare(args []string) error {
+	flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+	latitude := flags.Float64("lat", math.NaN(), "latitude of the mosque (required)")
+	longitude := flags.Float64("lon", math.NaN(), "longitude of the mosque (required)")
+	method := flags.String("method", "MUSLIM_WORLD_LEAGUE", "calculation method")
+	madhab := flags.String("madhab", "SHAFI", "madhab used to calculate Asr, SHAFI or HANAFI")
+	tzone := flags.String("tz", "UTC", "tz database time zone of the timetable")
+	threshold := flags.Duration("threshold", 5*time.Minute, "flag days with a difference above this")
+	format := flags.String("format", "", "format of the timetable, csv or json (default from the file extension)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected one timetable file")
+	}
+	path := flags.Arg(0)
+	if math.IsNaN(*latitude) || math.IsNaN(*longitude) {
+		return fmt.Errorf("-lat and -lon are required")
+	}
+
+	coords, err := util.NewCoordinates(*latitude, *longitude)
+	if err != nil {
+		return err
+	}
+	params, err := parseParams(*method, *madhab)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(*tzone)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	var days []*calc.PrayerTimes
+	switch strings.ToLower(*format) {
+	case "json":
+		days, err = timetable.ReadJSON(f, loc)
+	case "csv":
+		days, err = timetable.ReadCSV(f, loc)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	report, err := timetable.Compare(days, coords, params, *threshold)
+	if err != nil {
+		return err
+	}
+	return report.Write(os.Stdout)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+)
+
+func TestCompareRequiresCoordinates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timetable.csv")
+	assert.Nil(t, os.WriteFile(path, []byte("date,fajr,dhuhr\n2015-07-12,04:42,13:21\n"), 0o600))
+
+	for _, args := range [][]string{
+		{path},
+		{"-lat", "35.7750", path},
+		{"-lon", "-78.6336", path},
+	} {
+		err := compare(args)
+		assert.EqualError(t, err, "-lat and -lon are required", args)
+	}
+
+	assert.Nil(t, compare([]string{"-lat", "35.7750", "-lon", "-78.6336", "-method", "NORTH_AMERICA", "-tz", "America/New_York", path}))
+}
+
+func TestParseParams(t *testing.T) {
+	params, err := parseParams("karachi", "hanafi")
+	assert.Nil(t, err)
+	assert.Equal(t, calc.KARACHI, params.Method)
+	assert.Equal(t, calc.HANAFI, params.Madhab)
+
+	_, err = parseParams("ISNA", "SHAFI")
+	assert.ErrorIs(t, err, calc.ErrInvalidParameters)
+	_, err = parseParams("KARACHI", "MALIKI")
+	assert.ErrorIs(t, err, calc.ErrInvalidParameters)
+}
@@ -0,0 +1,44 @@
+// Command adhango works with prayer times from the command line.
+//
+// Usage:
+//
+//	adhango compare [flags] timetable.csv
+package main
+
+import (
+	"fmt"
+	"os"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+)
+
+var commands = map[string]func(args []string) error{
+	"compare": compare,
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		fmt.Fprintln(os.Stderr, "usage: adhango compare [flags] timetable")
+		os.Exit(2)
+	}
+	if err := commands[os.Args[1]](os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "adhango %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// parseParams returns the parameters of the calculation method and madhab named `method` and
+// `madhab`, case insensitively.
+func parseParams(method string, madhab string) (*calc.CalculationParameters, error) {
+	m, err := calc.ParseCalculationMethod(method)
+	if err != nil {
+		return nil, err
+	}
+	a, err := calc.ParseMadhab(madhab)
+	if err != nil {
+		return nil, err
+	}
+	params := calc.GetMethodParameters(m)
+	params.Madhab = a
+	return params, nil
+}
@@ -0,0 +1,72 @@
+// Package geojson reads the simplified boundaries embedded in the calc and geo packages, and finds
+// the one containing a point.
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Rings are the outer rings of polygons, as [longitude, latitude] positions.
+type Rings [][][2]float64
+
+// Feature is a GeoJSON Feature with a MultiPolygon geometry.
+type Feature struct {
+	// Properties of the feature, left for the caller to decode
+	Properties json.RawMessage
+
+	// Outer rings of the polygons of the feature. Holes are not kept.
+	Rings Rings
+}
+
+// ReadMultiPolygons reads a GeoJSON FeatureCollection of MultiPolygons. An error is returned if
+// any feature has another geometry or an empty polygon.
+func ReadMultiPolygons(b []byte) ([]Feature, error) {
+	var collection struct {
+		Features []struct {
+			Properties json.RawMessage `json:"properties"`
+			Geometry   struct {
+				Type        string           `json:"type"`
+				Coordinates [][][][2]float64 `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(b, &collection); err != nil {
+		return nil, err
+	}
+
+	features := make([]Feature, 0, len(collection.Features))
+	for i, f := range collection.Features {
+		if f.Geometry.Type != "MultiPolygon" || len(f.Geometry.Coordinates) == 0 {
+			return nil, fmt.Errorf("feature %d: expected a MultiPolygon", i)
+		}
+		feature := Feature{Properties: f.Properties}
+		for _, polygon := range f.Geometry.Coordinates {
+			if len(polygon) == 0 {
+				return nil, fmt.Errorf("feature %d: empty polygon", i)
+			}
+			feature.Rings = append(feature.Rings, polygon[0])
+		}
+		features = append(features, feature)
+	}
+	return features, nil
+}
+
+// Contains reports whether the point at `latitude` and `longitude` is inside one of the rings, by
+// counting the edges crossed by a ray going east.
+func (r Rings) Contains(latitude float64, longitude float64) bool {
+	x, y := longitude, latitude
+	for _, ring := range r {
+		inside := false
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			a, b := ring[i], ring[j]
+			if (a[1] > y) != (b[1] > y) && x < a[0]+(y-a[1])*(b[0]-a[0])/(b[1]-a[1]) {
+				inside = !inside
+			}
+		}
+		if inside {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package geojson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadMultiPolygons(t *testing.T) {
+	features, err := ReadMultiPolygons([]byte(`{"type":"FeatureCollection","features":[
+{"type":"Feature","properties":{"name":"square"},"geometry":{"type":"MultiPolygon","coordinates":[
+[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[4,4],[6,4],[6,6],[4,6],[4,4]]],
+[[[20,20],[30,20],[25,30],[20,20]]]]}}
+]}`))
+	assert.Nil(t, err)
+	assert.Len(t, features, 1)
+
+	var properties struct{ Name string }
+	assert.Nil(t, json.Unmarshal(features[0].Properties, &properties))
+	assert.Equal(t, "square", properties.Name)
+
+	rings := features[0].Rings
+	assert.Len(t, rings, 2)
+	assert.True(t, rings.Contains(1, 1))
+	assert.True(t, rings.Contains(5, 5)) // Holes are not kept.
+	assert.True(t, rings.Contains(22, 25))
+	assert.False(t, rings.Contains(11, 5))
+	assert.False(t, rings.Contains(29, 21))
+
+	_, err = ReadMultiPolygons([]byte(`{"features":[{"geometry":{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,1]]]}}]}`))
+	assert.NotNil(t, err)
+	_, err = ReadMultiPolygons([]byte(`{"features":[{"geometry":{"type":"MultiPolygon","coordinates":[[]]}}]}`))
+	assert.NotNil(t, err)
+}
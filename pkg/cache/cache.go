@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"sync"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// Cache stores the prayer times calculated by calc.NewPrayerTimes, evicting the least recently
+// used days once it is full. It is safe for concurrent use.
+//
+// Coordinates are rounded to a grid before calculating, so that nearby locations share entries.
+// Below 45 degrees of latitude, the default grid of 0.01 degrees, about a kilometre, moves the
+// unrounded times by up to 5 seconds, so a prayer time may be rounded to the minute before or
+// after the one calculated at the exact coordinates. Further from the equator, Fajr and Isha can
+// move by much more on the days the sun barely reaches their angles. SetPrecision sets a finer
+// grid.
+type Cache struct {
+	mu        sync.Mutex
+	capacity  int
+	precision float64
+	entries   map[key]*list.Element
+	order     *list.List
+	hits      uint64
+	misses    uint64
+}
+
+// key identifies an entry. Parameters with the same hash are told apart by comparing them with
+// the parameters of the entry.
+type key struct {
+	latitude  int64
+	longitude int64
+	date      data.DateComponents
+	params    uint64
+}
+
+type entry struct {
+	key         key
+	params      *calc.CalculationParameters
+	prayerTimes *calc.PrayerTimes
+}
+
+// Stats counts the lookups served from the cache and those that needed a calculation.
+type Stats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// NewCache creates a Cache holding the prayer times of up to `capacity` days across all
+// locations and parameters.
+func NewCache(capacity int) *Cache {
+	return &Cache{
+		capacity:  capacity,
+		precision: 0.01,
+		entries:   map[key]*list.Element{},
+		order:     list.New(),
+	}
+}
+
+// SetPrecision sets the size in degrees of the grid coordinates are rounded to. It should be set
+// before the Cache is used, as entries are not recalculated. An error wrapping
+// calc.ErrInvalidParameters is returned, and the precision left unchanged, unless `degrees` is
+// greater than 0.
+func (c *Cache) SetPrecision(degrees float64) error {
+	if !(degrees > 0) {
+		return fmt.Errorf("%w: precision must be greater than 0, got %v", calc.ErrInvalidParameters, degrees)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.precision = degrees
+	return nil
+}
+
+// PrayerTimes returns the prayer times of `date` at `coords`, rounded to the grid of the Cache,
+// calculating them with `params` if they are not cached. The returned PrayerTimes are a copy that
+// belongs to the caller.
+func (c *Cache) PrayerTimes(coords *util.Coordinates, date *data.DateComponents, params *calc.CalculationParameters) (*calc.PrayerTimes, error) {
+	if coords == nil || date == nil || params == nil {
+		return nil, fmt.Errorf("%w: coordinates, date and parameters must not be nil", calc.ErrInvalidParameters)
+	}
+	k, snapped := c.key(coords, date, params)
+
+	c.mu.Lock()
+	if element, ok := c.entries[k]; ok && element.Value.(*entry).params.Equal(params) {
+		c.order.MoveToFront(element)
+		c.hits++
+		prayerTimes := element.Value.(*entry).prayerTimes
+		c.mu.Unlock()
+		return copyPrayerTimes(prayerTimes), nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// Calculated outside of the lock, so that a slow calculation does not hold up lookups of
+	// other days. Concurrent misses of the same day may both calculate it. The parameters are
+	// copied, so that later changes by the caller do not change the entry.
+	cloned := params.Clone()
+	params = &cloned
+	gridPoint := snapped
+	prayerTimes, err := calc.NewPrayerTimes(&gridPoint, date, params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.add(k, params, prayerTimes)
+	c.mu.Unlock()
+	return copyPrayerTimes(prayerTimes), nil
+}
+
+// Precompute calculates and caches the prayer times of `days` days at `coords` starting at
+// `from`, e.g. 365 to serve a year ahead from the cache. The days share their solar computations
+// through a calc.SolarCalendar. Nothing is cached if any of the days cannot be calculated.
+func (c *Cache) Precompute(coords *util.Coordinates, from *data.DateComponents, days int, params *calc.CalculationParameters) error {
+	if coords == nil || from == nil || params == nil {
+		return fmt.Errorf("%w: coordinates, date and parameters must not be nil", calc.ErrInvalidParameters)
+	}
+	k, snapped := c.key(coords, from, params)
+	cloned := params.Clone()
+	params = &cloned
+	timetable, err := calc.NewSolarCalendar(&snapped, from, days).Timetable(params)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, prayerTimes := range timetable {
+		k.date = *prayerTimes.DateComponent
+		c.add(k, params, prayerTimes)
+	}
+	return nil
+}
+
+// Source returns a calc.TimetableSource taking the prayer times at `coords` from the Cache. The
+// prayer times of adjacent days, e.g. for TimeUntilNextPrayer, are taken from the Cache as well.
+func (c *Cache) Source(coords *util.Coordinates, params *calc.CalculationParameters) calc.TimetableSource {
+	return &source{cache: c, coords: coords, params: params}
+}
+
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{Hits: c.hits, Misses: c.misses, Entries: c.order.Len()}
+}
+
+// Clear removes every entry, e.g. after a change to how prayer times are calculated.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[key]*list.Element{}
+	c.order.Init()
+}
+
+// add stores `prayerTimes` calculated with `params`, replacing an entry with the same key and
+// evicting the least recently used entry if the Cache is full. The caller must hold the lock.
+func (c *Cache) add(k key, params *calc.CalculationParameters, prayerTimes *calc.PrayerTimes) {
+	if element, ok := c.entries[k]; ok {
+		element.Value = &entry{key: k, params: params, prayerTimes: prayerTimes}
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[k] = c.order.PushFront(&entry{key: k, params: params, prayerTimes: prayerTimes})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+}
+
+// key returns the key of the entry for `coords`, `date` and `params`, and the coordinates of the
+// grid point the prayer times of the entry are calculated at.
+func (c *Cache) key(coords *util.Coordinates, date *data.DateComponents, params *calc.CalculationParameters) (key, util.Coordinates) {
+	c.mu.Lock()
+	precision := c.precision
+	c.mu.Unlock()
+
+	k := key{
+		latitude:  int64(math.Round(coords.Latitude / precision)),
+		longitude: int64(math.Round(coords.Longitude / precision)),
+		date:      data.DateComponents{Year: date.Year, Month: date.Month, Day: date.Day},
+		params:    params.Hash(),
+	}
+	return k, util.Coordinates{Latitude: float64(k.latitude) * precision, Longitude: float64(k.longitude) * precision}
+}
+
+// copyPrayerTimes returns a copy of `p` that can be modified, e.g. by SetTimeZone, without
+// changing the cached entry. The copy is made in a single allocation, unless the parameters have
+// seasonal intervals or overrides.
+func copyPrayerTimes(p *calc.PrayerTimes) *calc.PrayerTimes {
+	c := &struct {
+		prayerTimes calc.PrayerTimes
+		coords      util.Coordinates
+		date        data.DateComponents
+		params      calc.CalculationParameters
+		details     calc.PrayerTimesDetails
+	}{
+		prayerTimes: *p,
+		coords:      *p.Coords,
+		date:        *p.DateComponent,
+		params:      p.CalculationParams.Clone(),
+	}
+	c.prayerTimes.Coords = &c.coords
+	c.prayerTimes.DateComponent = &c.date
+	c.prayerTimes.CalculationParams = &c.params
+	if p.Details != nil {
+		c.details = *p.Details
+		c.prayerTimes.Details = &c.details
+	}
+	return &c.prayerTimes
+}
+
+type source struct {
+	cache  *Cache
+	coords *util.Coordinates
+	params *calc.CalculationParameters
+}
+
+func (s *source) PrayerTimes(date *data.DateComponents) (*calc.PrayerTimes, error) {
+	prayerTimes, err := s.cache.PrayerTimes(s.coords, date, s.params)
+	if err != nil {
+		return nil, err
+	}
+	prayerTimes.SetSource(s)
+	return prayerTimes, nil
+}
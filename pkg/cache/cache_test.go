@@ -0,0 +1,328 @@
+package cache
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+func raleigh() *util.Coordinates {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	return coords
+}
+
+func date(day int) *data.DateComponents {
+	return data.NewDateComponents(time.Date(2015, time.July, day, 0, 0, 0, 0, time.UTC))
+}
+
+func TestCacheHitsAndMisses(t *testing.T) {
+	c := NewCache(10)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+
+	first, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	second, err := c.PrayerTimes(raleigh(), date(12), calc.GetMethodParameters(calc.NORTH_AMERICA))
+	assert.Nil(t, err)
+	assert.Equal(t, first.Fajr, second.Fajr)
+	assert.Equal(t, Stats{Hits: 1, Misses: 1, Entries: 1}, c.Stats())
+
+	params.Madhab = calc.HANAFI
+	third, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	assert.True(t, third.Asr.After(first.Asr))
+	assert.Equal(t, Stats{Hits: 1, Misses: 2, Entries: 2}, c.Stats())
+}
+
+func TestCacheMatchesNewPrayerTimes(t *testing.T) {
+	c := NewCache(10)
+	params := calc.GetMethodParameters(calc.MUSLIM_WORLD_LEAGUE)
+
+	cached, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	calculated, err := calc.NewPrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	for _, prayer := range []calc.Prayer{calc.FAJR, calc.SUNRISE, calc.DHUHR, calc.ASR, calc.MAGHRIB, calc.ISHA} {
+		assert.Equal(t, calculated.TimeForPrayer(prayer), cached.TimeForPrayer(prayer), prayer)
+	}
+}
+
+func TestCacheHitAllocations(t *testing.T) {
+	c := NewCache(10)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+	coords, day := raleigh(), date(12)
+	_, err := c.PrayerTimes(coords, day, params)
+	assert.Nil(t, err)
+
+	// Only the returned copy is allocated.
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = c.PrayerTimes(coords, day, params)
+	})
+	assert.Equal(t, 1.0, allocs)
+}
+
+func TestCacheComparesParameters(t *testing.T) {
+	c := NewCache(10)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+
+	_, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+
+	// Other parameters under the same key, as after a collision of their hashes.
+	for _, element := range c.entries {
+		element.Value.(*entry).params = calc.GetMethodParameters(calc.KARACHI)
+	}
+	cached, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	assert.Equal(t, Stats{Hits: 0, Misses: 2, Entries: 1}, c.Stats())
+
+	calculated, err := calc.NewPrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	assert.Equal(t, calculated.Isha, cached.Isha)
+
+	_, err = c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), c.Stats().Hits)
+}
+
+func TestCacheQuantizationError(t *testing.T) {
+	params := calc.GetMethodParameters(calc.MUSLIM_WORLD_LEAGUE)
+	prayers := []calc.Prayer{calc.FAJR, calc.SUNRISE, calc.DHUHR, calc.ASR, calc.MAGHRIB, calc.ISHA}
+
+	// Points between grid points of the default grid, below 45 degrees of latitude.
+	for latitude := -44.996; latitude < 45; latitude += 4.7 {
+		for longitude := -179.996; longitude < 180; longitude += 13.1 {
+			coords := &util.Coordinates{Latitude: latitude, Longitude: longitude}
+			for _, day := range []int{1, 15, 29} {
+				cached, err := NewCache(1).PrayerTimes(coords, date(day), params)
+				assert.Nil(t, err)
+				calculated, err := calc.NewPrayerTimes(coords, date(day), params)
+				assert.Nil(t, err)
+
+				for _, prayer := range prayers {
+					unrounded := cached.Details.TimeForPrayer(prayer).Sub(calculated.Details.TimeForPrayer(prayer))
+					assert.LessOrEqual(t, math.Abs(unrounded.Seconds()), 5.0, coords, prayer)
+
+					rounded := cached.TimeForPrayer(prayer).Sub(calculated.TimeForPrayer(prayer))
+					assert.LessOrEqual(t, math.Abs(rounded.Minutes()), 1.0, coords, prayer)
+				}
+			}
+		}
+	}
+}
+
+func TestCacheQuantizesCoordinates(t *testing.T) {
+	c := NewCache(10)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+
+	nearby, _ := util.NewCoordinates(35.7761, -78.6331)
+	_, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	_, err = c.PrayerTimes(nearby, date(12), params)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), c.Stats().Hits)
+
+	c = NewCache(10)
+	assert.Nil(t, c.SetPrecision(0.001))
+	_, err = c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	_, err = c.PrayerTimes(nearby, date(12), params)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), c.Stats().Hits)
+}
+
+func TestCacheRejectsInvalidPrecision(t *testing.T) {
+	c := NewCache(10)
+	for _, degrees := range []float64{0, -0.01, math.NaN()} {
+		assert.ErrorIs(t, c.SetPrecision(degrees), calc.ErrInvalidParameters, degrees)
+	}
+
+	// The default precision is kept.
+	nearby, _ := util.NewCoordinates(35.7761, -78.6331)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+	_, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	_, err = c.PrayerTimes(nearby, date(12), params)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), c.Stats().Hits)
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewCache(2)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+
+	for _, day := range []int{12, 13, 12, 14} {
+		_, err := c.PrayerTimes(raleigh(), date(day), params)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 2, c.Stats().Entries)
+
+	// 13 was the least recently used when 14 was added.
+	_, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	_, err = c.PrayerTimes(raleigh(), date(13), params)
+	assert.Nil(t, err)
+	assert.Equal(t, Stats{Hits: 2, Misses: 4, Entries: 2}, c.Stats())
+}
+
+func TestCacheReturnsCopies(t *testing.T) {
+	c := NewCache(10)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+
+	first, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	assert.Nil(t, first.SetTimeZone("America/New_York"))
+
+	second, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	assert.Equal(t, time.UTC, second.Fajr.Location())
+	assert.Equal(t, time.UTC, second.Details.Fajr.Location())
+}
+
+func TestCacheCopiesParameters(t *testing.T) {
+	c := NewCache(10)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+	params.SeasonalOverrides = []calc.SeasonalOverride{{
+		Calendar:    calc.GREGORIAN,
+		From:        calc.MonthDay{Month: 1, Day: 1},
+		To:          calc.MonthDay{Month: 1, Day: 31},
+		Adjustments: &calc.PrayerAdjustments{FajrAdj: 5},
+	}}
+
+	first, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	first.CalculationParams.SeasonalOverrides[0].Adjustments.FajrAdj = 10
+	first.CalculationParams.Madhab = calc.HANAFI
+
+	second, err := c.PrayerTimes(raleigh(), date(12), params)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, second.CalculationParams.SeasonalOverrides[0].Adjustments.FajrAdj)
+	assert.Equal(t, calc.SHAFI_HANBALI_MALIKI, second.CalculationParams.Madhab)
+
+	// Changes by the caller to the parameters it passed in do not reach the entry either.
+	params.SeasonalOverrides[0].Adjustments.FajrAdj = 10
+	third, err := c.PrayerTimes(raleigh(), date(12), calc.GetMethodParameters(calc.NORTH_AMERICA))
+	assert.Nil(t, err)
+	assert.Equal(t, Stats{Hits: 1, Misses: 2, Entries: 2}, c.Stats())
+	assert.Nil(t, third.CalculationParams.SeasonalOverrides)
+}
+
+func TestCachePrecompute(t *testing.T) {
+	c := NewCache(400)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+
+	assert.Nil(t, c.Precompute(raleigh(), date(1), 365, params))
+	assert.Equal(t, Stats{Entries: 365}, c.Stats())
+
+	source := c.Source(raleigh(), params)
+	_, err := source.PrayerTimes(data.NewDateComponents(time.Date(2016, time.June, 29, 0, 0, 0, 0, time.UTC)))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), c.Stats().Hits)
+
+	c.Clear()
+	assert.Equal(t, 0, c.Stats().Entries)
+}
+
+func TestCacheSourceAdjacentDays(t *testing.T) {
+	c := NewCache(10)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+	assert.Nil(t, c.Precompute(raleigh(), date(11), 3, params))
+
+	prayerTimes, err := c.Source(raleigh(), params).PrayerTimes(date(12))
+	assert.Nil(t, err)
+
+	prayer, _, err := prayerTimes.TimeUntilNextPrayer(prayerTimes.Isha.Add(time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, calc.FAJR, prayer)
+	prayer, _, err = prayerTimes.TimeSinceCurrentPrayer(prayerTimes.Fajr.Add(-time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, calc.ISHA, prayer)
+	assert.Equal(t, Stats{Hits: 3, Misses: 0, Entries: 3}, c.Stats())
+}
+
+func TestCacheConcurrentAccess(t *testing.T) {
+	c := NewCache(20)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for day := 1; day <= 31; day++ {
+				_, err := c.PrayerTimes(raleigh(), date(day), params)
+				assert.Nil(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(8*31), stats.Hits+stats.Misses)
+	assert.Equal(t, 20, stats.Entries)
+}
+
+// month returns the days of July 2015.
+func month() []*data.DateComponents {
+	var dates []*data.DateComponents
+	for day := 1; day <= 31; day++ {
+		dates = append(dates, date(day))
+	}
+	return dates
+}
+
+func BenchmarkNewPrayerTimes(b *testing.B) {
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+	coords, dates := raleigh(), month()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := calc.NewPrayerTimes(coords, dates[i%31], params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCacheHit(b *testing.B) {
+	c := NewCache(100)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+	if err := c.Precompute(raleigh(), date(1), 31, params); err != nil {
+		b.Fatal(err)
+	}
+
+	coords, dates := raleigh(), month()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.PrayerTimes(coords, dates[i%31], params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCacheHitParallel(b *testing.B) {
+	c := NewCache(100)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+	if err := c.Precompute(raleigh(), date(1), 31, params); err != nil {
+		b.Fatal(err)
+	}
+
+	coords, dates := raleigh(), month()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := c.PrayerTimes(coords, dates[i%31], params); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
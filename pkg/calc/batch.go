@@ -0,0 +1,59 @@
+package calc
+
+import (
+	"fmt"
+
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// NewPrayerTimesForMethods calculates the prayer times of `date` at `coords` with each of
+// `params`, keyed by their Method, e.g. to compare the calculation methods. The position of the
+// sun, sunrise, sunset and the length of the night are calculated once and shared, so this is much
+// faster than calling NewPrayerTimes for each.
+//
+// Each Method may appear only once. An error is returned if the times of any of the parameters
+// cannot be calculated.
+func NewPrayerTimesForMethods(coords *util.Coordinates, date *data.DateComponents, params []*CalculationParameters) (map[CalculationMethod]*PrayerTimes, error) {
+	if coords == nil || date == nil {
+		return nil, fmt.Errorf("%w: coordinates and date must not be nil", ErrInvalidParameters)
+	}
+	tomorrow := data.NewDateComponents(data.ResolveTimeByDateComponents(date).AddDate(0, 0, 1))
+	solarTime, tomorrowSolarTime := solarTimes(date, tomorrow, coords)
+	return prayerTimesForMethods(coords, date, params, solarTime, tomorrowSolarTime)
+}
+
+// PrayerTimesForMethods is NewPrayerTimesForMethods using the solar times of the calendar.
+func (s *SolarCalendar) PrayerTimesForMethods(date *data.DateComponents, params []*CalculationParameters) (map[CalculationMethod]*PrayerTimes, error) {
+	if date == nil {
+		return nil, fmt.Errorf("%w: date must not be nil", ErrInvalidParameters)
+	}
+	i, ok := s.index(date)
+	if !ok {
+		return NewPrayerTimesForMethods(s.coords, date, params)
+	}
+	return prayerTimesForMethods(s.coords, date, params, s.solarTimes[i], s.solarTimes[i+1])
+}
+
+func prayerTimesForMethods(coords *util.Coordinates, date *data.DateComponents, params []*CalculationParameters, solarTime *util.SolarTime, tomorrowSolarTime *util.SolarTime) (map[CalculationMethod]*PrayerTimes, error) {
+	day, err := newSolarDay(date, solarTime, tomorrowSolarTime)
+	if err != nil {
+		return nil, err
+	}
+	prayerTimes := make(map[CalculationMethod]*PrayerTimes, len(params))
+	for _, p := range params {
+		if p == nil {
+			return nil, fmt.Errorf("%w: parameters must not be nil", ErrInvalidParameters)
+		}
+		if _, ok := prayerTimes[p.Method]; ok {
+			return nil, fmt.Errorf("%w: method %v appears more than once", ErrInvalidParameters, p.Method)
+		}
+
+		times, err := prayerTimesFromSolarDay(coords, date, p, day, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", p.Method, err)
+		}
+		prayerTimes[p.Method] = times
+	}
+	return prayerTimes, nil
+}
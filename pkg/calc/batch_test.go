@@ -0,0 +1,94 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+func allMethodParameters() []*CalculationParameters {
+	var params []*CalculationParameters
+	for method := MUSLIM_WORLD_LEAGUE; method <= UOIF; method++ {
+		params = append(params, GetMethodParameters(method))
+	}
+	return params
+}
+
+func TestNewPrayerTimesForMethods(t *testing.T) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	date := data.NewDateComponents(time.Date(2015, time.July, 12, 0, 0, 0, 0, time.UTC))
+
+	params := allMethodParameters()
+	params[0].Madhab = HANAFI
+	params[1].HighLatitudeRule = TWILIGHT_ANGLE
+	byMethod, err := NewPrayerTimesForMethods(coords, date, params)
+	assert.Nil(t, err)
+	assert.Len(t, byMethod, len(params))
+
+	for _, p := range params {
+		expected, err := NewPrayerTimes(coords, date, p)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, byMethod[p.Method], p.Method)
+	}
+}
+
+func TestNewPrayerTimesForMethodsErrors(t *testing.T) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	date := data.NewDateComponents(time.Date(2015, time.July, 12, 0, 0, 0, 0, time.UTC))
+
+	_, err := NewPrayerTimesForMethods(coords, date, []*CalculationParameters{GetMethodParameters(KARACHI), GetMethodParameters(KARACHI)})
+	assert.ErrorIs(t, err, ErrInvalidParameters)
+	assert.Contains(t, err.Error(), "Karachi")
+
+	_, err = NewPrayerTimesForMethods(coords, date, []*CalculationParameters{nil})
+	assert.ErrorIs(t, err, ErrInvalidParameters)
+
+	// Polar night
+	tromso, _ := util.NewCoordinates(69.6492, 18.9553)
+	winter := data.NewDateComponents(time.Date(2015, time.December, 21, 0, 0, 0, 0, time.UTC))
+	_, err = NewPrayerTimesForMethods(tromso, winter, []*CalculationParameters{GetMethodParameters(KARACHI), GetMethodParameters(EGYPTIAN)})
+	assert.ErrorIs(t, err, ErrNoSunrise)
+}
+
+func TestSolarCalendarPrayerTimesForMethods(t *testing.T) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	from := data.NewDateComponents(time.Date(2015, time.July, 1, 0, 0, 0, 0, time.UTC))
+	calendar := NewSolarCalendar(coords, from, 31)
+
+	for _, day := range []int{12, 31} {
+		date := data.NewDateComponents(time.Date(2015, time.July, day, 0, 0, 0, 0, time.UTC))
+		expected, err := NewPrayerTimesForMethods(coords, date, allMethodParameters())
+		assert.Nil(t, err)
+		actual, err := calendar.PrayerTimesForMethods(date, allMethodParameters())
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func BenchmarkNewPrayerTimesAllMethods(b *testing.B) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	date := data.NewDateComponents(time.Date(2015, time.July, 12, 0, 0, 0, 0, time.UTC))
+	params := allMethodParameters()
+	for i := 0; i < b.N; i++ {
+		for _, p := range params {
+			if _, err := NewPrayerTimes(coords, date, p); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkNewPrayerTimesForMethods(b *testing.B) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	date := data.NewDateComponents(time.Date(2015, time.July, 12, 0, 0, 0, 0, time.UTC))
+	params := allMethodParameters()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewPrayerTimesForMethods(coords, date, params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package calc
 
+import (
+	"fmt"
+	"strings"
+)
+
 type CalculationMethod int64
 
 const (
@@ -41,62 +46,137 @@ const (
 	UOIF
 )
 
+func (m CalculationMethod) String() string {
+	switch m {
+	case OTHER:
+		return "Other"
+	case MUSLIM_WORLD_LEAGUE:
+		return "MuslimWorldLeague"
+	case EGYPTIAN:
+		return "Egyptian"
+	case KARACHI:
+		return "Karachi"
+	case UMM_AL_QURA:
+		return "UmmAlQura"
+	case DUBAI:
+		return "Dubai"
+	case MOON_SIGHTING_COMMITTEE:
+		return "MoonSightingCommittee"
+	case NORTH_AMERICA:
+		return "NorthAmerica"
+	case KUWAIT:
+		return "Kuwait"
+	case QATAR:
+		return "Qatar"
+	case SINGAPORE:
+		return "Singapore"
+	case UOIF:
+		return "UOIF"
+	}
+	return "Unknown"
+}
+
+// ParseCalculationMethod returns the method named `name`, the name of its constant in any case,
+// e.g. "MUSLIM_WORLD_LEAGUE" or "karachi". An error wrapping ErrInvalidParameters is returned for
+// other names, including OTHER, which has no parameters of its own.
+func ParseCalculationMethod(name string) (CalculationMethod, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "MUSLIM_WORLD_LEAGUE":
+		return MUSLIM_WORLD_LEAGUE, nil
+	case "EGYPTIAN":
+		return EGYPTIAN, nil
+	case "KARACHI":
+		return KARACHI, nil
+	case "UMM_AL_QURA":
+		return UMM_AL_QURA, nil
+	case "DUBAI":
+		return DUBAI, nil
+	case "MOON_SIGHTING_COMMITTEE":
+		return MOON_SIGHTING_COMMITTEE, nil
+	case "NORTH_AMERICA":
+		return NORTH_AMERICA, nil
+	case "KUWAIT":
+		return KUWAIT, nil
+	case "QATAR":
+		return QATAR, nil
+	case "SINGAPORE":
+		return SINGAPORE, nil
+	case "UOIF":
+		return UOIF, nil
+	}
+	return OTHER, fmt.Errorf("%w: unknown calculation method %q", ErrInvalidParameters, name)
+}
+
+// GetMethodParameters returns new parameters of `method`, which the caller may modify. See
+// MethodParameters.
 func GetMethodParameters(method CalculationMethod) *CalculationParameters {
-	cpb := NewCalculationParametersBuilder().SetMethod(method)
+	params := MethodParameters(method)
+	return &params
+}
+
+// MethodParameters returns the parameters of `method` by value, without allocating. Parameters of
+// an unknown method are the defaults of NewCalculationParametersBuilder.
+func MethodParameters(method CalculationMethod) CalculationParameters {
+	params := CalculationParameters{
+		Method:                  method,
+		FajrIntervalCombination: USE_INTERVAL,
+		IshaIntervalCombination: USE_INTERVAL,
+		Madhab:                  SHAFI_HANBALI_MALIKI,
+		HighLatitudeRule:        MIDDLE_OF_THE_NIGHT,
+	}
 	switch method {
 	case MUSLIM_WORLD_LEAGUE:
-		cpb.SetFajrAngle(18.0).
-			SetIshaAngle(17.0).
-			SetMethodAdjustments(PrayerAdjustments{DhuhrAdj: 1})
+		params.FajrAngle = 18.0
+		params.IshaAngle = 17.0
+		params.MethodAdjustments = PrayerAdjustments{DhuhrAdj: 1}
 	case EGYPTIAN:
-		cpb.SetFajrAngle(19.5).
-			SetIshaAngle(17.5).
-			SetMethodAdjustments(PrayerAdjustments{DhuhrAdj: 1})
+		params.FajrAngle = 19.5
+		params.IshaAngle = 17.5
+		params.MethodAdjustments = PrayerAdjustments{DhuhrAdj: 1}
 	case KARACHI:
-		cpb.SetFajrAngle(18.0).
-			SetIshaAngle(18.0).
-			SetMethodAdjustments(PrayerAdjustments{DhuhrAdj: 1})
+		params.FajrAngle = 18.0
+		params.IshaAngle = 18.0
+		params.MethodAdjustments = PrayerAdjustments{DhuhrAdj: 1}
 	case UMM_AL_QURA:
-		cpb.SetFajrAngle(18.5).
-			SetIshaInterval(90)
+		params.FajrAngle = 18.5
+		params.IshaInterval = 90
 	case DUBAI:
-		cpb.SetFajrAngle(18.2).
-			SetIshaAngle(18.2).
-			SetMethodAdjustments(PrayerAdjustments{
-				SunriseAdj: -3,
-				DhuhrAdj:   3,
-				AsrAdj:     3,
-				MaghribAdj: 3,
-			})
+		params.FajrAngle = 18.2
+		params.IshaAngle = 18.2
+		params.MethodAdjustments = PrayerAdjustments{
+			SunriseAdj: -3,
+			DhuhrAdj:   3,
+			AsrAdj:     3,
+			MaghribAdj: 3,
+		}
 	case MOON_SIGHTING_COMMITTEE:
-		cpb.SetFajrAngle(18.0).
-			SetIshaAngle(18.0).
-			SetMethodAdjustments(PrayerAdjustments{
-				DhuhrAdj:   5,
-				MaghribAdj: 3,
-			})
+		params.FajrAngle = 18.0
+		params.IshaAngle = 18.0
+		params.MethodAdjustments = PrayerAdjustments{
+			DhuhrAdj:   5,
+			MaghribAdj: 3,
+		}
 	case NORTH_AMERICA:
-		cpb.SetFajrAngle(15.0).
-			SetIshaAngle(15.0).
-			SetMethodAdjustments(PrayerAdjustments{
-				DhuhrAdj: 1,
-			})
+		params.FajrAngle = 15.0
+		params.IshaAngle = 15.0
+		params.MethodAdjustments = PrayerAdjustments{
+			DhuhrAdj: 1,
+		}
 	case KUWAIT:
-		cpb.SetFajrAngle(18.0).
-			SetIshaAngle(17.5)
+		params.FajrAngle = 18.0
+		params.IshaAngle = 17.5
 	case QATAR:
-		cpb.SetFajrAngle(18.0).
-			SetIshaInterval(90)
+		params.FajrAngle = 18.0
+		params.IshaInterval = 90
 	case SINGAPORE:
-		cpb.SetFajrAngle(20.0).
-			SetIshaAngle(18.0).
-			SetMethodAdjustments(PrayerAdjustments{
-				DhuhrAdj: 1,
-			})
+		params.FajrAngle = 20.0
+		params.IshaAngle = 18.0
+		params.MethodAdjustments = PrayerAdjustments{
+			DhuhrAdj: 1,
+		}
 	case UOIF:
-		cpb.SetFajrAngle(12.0).
-			SetIshaAngle(12.0)
+		params.FajrAngle = 12.0
+		params.IshaAngle = 12.0
 	}
-
-	return cpb.Build()
+	return params
 }
@@ -67,3 +67,120 @@ func TestCalculationMethods(t *testing.T) {
 	assert.Equal(t, 0, params.IshaInterval)
 	assert.Equal(t, OTHER, params.Method)
 }
+
+func TestMethodParameters(t *testing.T) {
+	// The parameters built by GetMethodParameters before the methods were stored as values.
+	expected := func(method CalculationMethod, fajrAngle float64, ishaAngle float64, ishaInterval int, adjustments PrayerAdjustments) CalculationParameters {
+		return CalculationParameters{
+			Method:                  method,
+			FajrAngle:               fajrAngle,
+			FajrInterval:            0,
+			FajrIntervalCombination: USE_INTERVAL,
+			IshaAngle:               ishaAngle,
+			IshaInterval:            ishaInterval,
+			IshaIntervalCombination: USE_INTERVAL,
+			Madhab:                  SHAFI_HANBALI_MALIKI,
+			HighLatitudeRule:        MIDDLE_OF_THE_NIGHT,
+			MethodAdjustments:       adjustments,
+		}
+	}
+	for _, want := range []CalculationParameters{
+		expected(MUSLIM_WORLD_LEAGUE, 18.0, 17.0, 0, PrayerAdjustments{DhuhrAdj: 1}),
+		expected(EGYPTIAN, 19.5, 17.5, 0, PrayerAdjustments{DhuhrAdj: 1}),
+		expected(KARACHI, 18.0, 18.0, 0, PrayerAdjustments{DhuhrAdj: 1}),
+		expected(UMM_AL_QURA, 18.5, 0.0, 90, PrayerAdjustments{}),
+		expected(DUBAI, 18.2, 18.2, 0, PrayerAdjustments{SunriseAdj: -3, DhuhrAdj: 3, AsrAdj: 3, MaghribAdj: 3}),
+		expected(MOON_SIGHTING_COMMITTEE, 18.0, 18.0, 0, PrayerAdjustments{DhuhrAdj: 5, MaghribAdj: 3}),
+		expected(NORTH_AMERICA, 15.0, 15.0, 0, PrayerAdjustments{DhuhrAdj: 1}),
+		expected(KUWAIT, 18.0, 17.5, 0, PrayerAdjustments{}),
+		expected(QATAR, 18.0, 0.0, 90, PrayerAdjustments{}),
+		expected(SINGAPORE, 20.0, 18.0, 0, PrayerAdjustments{DhuhrAdj: 1}),
+		expected(UOIF, 12.0, 12.0, 0, PrayerAdjustments{}),
+		expected(OTHER, 0.0, 0.0, 0, PrayerAdjustments{}),
+	} {
+		assert.Equal(t, want, MethodParameters(want.Method), want.Method)
+		assert.Equal(t, want, *GetMethodParameters(want.Method), want.Method)
+	}
+
+	// Modifying the parameters of one call does not change those of the next.
+	params := GetMethodParameters(KARACHI)
+	params.FajrAngle = 10
+	params.MethodAdjustments.DhuhrAdj = 10
+	assert.Equal(t, MethodParameters(KARACHI), *GetMethodParameters(KARACHI))
+	assert.InDelta(t, 18.0, GetMethodParameters(KARACHI).FajrAngle, 0.000001)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = MethodParameters(DUBAI)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func TestParseNames(t *testing.T) {
+	for name, method := range map[string]CalculationMethod{
+		"MUSLIM_WORLD_LEAGUE": MUSLIM_WORLD_LEAGUE, "egyptian": EGYPTIAN, "Karachi": KARACHI, "UMM_AL_QURA": UMM_AL_QURA,
+		"DUBAI": DUBAI, "moon_sighting_committee": MOON_SIGHTING_COMMITTEE, "NORTH_AMERICA": NORTH_AMERICA, "KUWAIT": KUWAIT,
+		"QATAR": QATAR, "SINGAPORE": SINGAPORE, " uoif ": UOIF,
+	} {
+		parsed, err := ParseCalculationMethod(name)
+		assert.Nil(t, err, name)
+		assert.Equal(t, method, parsed, name)
+	}
+	for _, name := range []string{"", "OTHER", "MuslimWorldLeague"} {
+		_, err := ParseCalculationMethod(name)
+		assert.ErrorIs(t, err, ErrInvalidParameters, name)
+	}
+
+	madhab, err := ParseMadhab("shafi")
+	assert.Nil(t, err)
+	assert.Equal(t, SHAFI_HANBALI_MALIKI, madhab)
+	madhab, err = ParseMadhab("SHAFI_HANBALI_MALIKI")
+	assert.Nil(t, err)
+	assert.Equal(t, SHAFI_HANBALI_MALIKI, madhab)
+	madhab, err = ParseMadhab("HANAFI")
+	assert.Nil(t, err)
+	assert.Equal(t, HANAFI, madhab)
+	_, err = ParseMadhab("MALIKI")
+	assert.ErrorIs(t, err, ErrInvalidParameters)
+
+	rule, err := ParseHighLatitudeRule("seventh_of_the_night")
+	assert.Nil(t, err)
+	assert.Equal(t, SEVENTH_OF_THE_NIGHT, rule)
+	rule, err = ParseHighLatitudeRule("TWILIGHT_ANGLE")
+	assert.Nil(t, err)
+	assert.Equal(t, TWILIGHT_ANGLE, rule)
+	_, err = ParseHighLatitudeRule("SEVENTH")
+	assert.ErrorIs(t, err, ErrInvalidParameters)
+}
+
+// Results of the benchmarks, so that the compiler does not optimise the calls away.
+var (
+	paramsSink      *CalculationParameters
+	paramsValueSink CalculationParameters
+)
+
+// BenchmarkBuildMethodParameters builds parameters the way GetMethodParameters used to.
+func BenchmarkBuildMethodParameters(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		paramsSink = NewCalculationParametersBuilder().
+			SetMethod(MUSLIM_WORLD_LEAGUE).
+			SetFajrAngle(18.0).
+			SetIshaAngle(17.0).
+			SetMethodAdjustments(PrayerAdjustments{DhuhrAdj: 1}).
+			Build()
+	}
+}
+
+func BenchmarkGetMethodParameters(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		paramsSink = GetMethodParameters(MUSLIM_WORLD_LEAGUE)
+	}
+}
+
+func BenchmarkMethodParameters(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		paramsValueSink = MethodParameters(MUSLIM_WORLD_LEAGUE)
+	}
+}
@@ -1,7 +1,5 @@
 package calc
 
-import "fmt"
-
 type CalculationParameters struct {
 	//  The method used to do the calculation
 	Method CalculationMethod
@@ -9,21 +7,44 @@ type CalculationParameters struct {
 	// The angle of the sun used to calculate fajr
 	FajrAngle float64
 
+	// Minutes before sunrise (if set, the time for Fajr will be Sunrise minus FajrInterval)
+	FajrInterval int
+
+	// How the FajrInterval is combined with the angle based time of Fajr
+	FajrIntervalCombination IntervalCombination
+
 	// The angle of the sun used to calculate isha
 	IshaAngle float64
 
 	// Minutes after Maghrib (if set, the time for Isha will be Maghrib plus IshaInterval)
 	IshaInterval int
 
+	// How the IshaInterval is combined with the angle based time of Isha
+	IshaIntervalCombination IntervalCombination
+
+	// Used to replace FajrInterval or IshaInterval during parts of the year, e.g. Ramadan
+	SeasonalIntervals []SeasonalInterval
+
 	// The juristic method used to calculate Asr
 	Madhab AsrJuristicMethod
 
+	// Shadow length, as a multiple of the length of the object, used to calculate Asr in place of
+	// the one of the Madhab if greater than 0
+	AsrShadowFactor float64
+
+	// Shadow length, as a multiple of the length of the object, at which the time of Asr ends. Used
+	// to calculate AsrEnd if greater than 0
+	AsrEndShadowFactor float64
+
 	// Rules for placing bounds on Fajr and Isha for high latitude areas
 	HighLatitudeRule HighLatitudeRule
 
 	// Used to optionally add or subtract a set amount of time from each prayer time
 	Adjustments PrayerAdjustments
 
+	// Used to replace the angles or Adjustments during parts of the year
+	SeasonalOverrides []SeasonalOverride
+
 	// Used for method adjustments
 	MethodAdjustments PrayerAdjustments
 }
@@ -35,35 +56,65 @@ type CalculationParametersBuilder struct {
 	// The angle of the sun used to calculate fajr
 	FajrAngle float64
 
+	// Minutes before sunrise (if set, the time for Fajr will be Sunrise minus FajrInterval)
+	FajrInterval int
+
+	// How the FajrInterval is combined with the angle based time of Fajr
+	FajrIntervalCombination IntervalCombination
+
 	// The angle of the sun used to calculate isha
 	IshaAngle float64
 
 	// Minutes after Maghrib (if set, the time for Isha will be Maghrib plus IshaInterval)
 	IshaInterval int
 
+	// How the IshaInterval is combined with the angle based time of Isha
+	IshaIntervalCombination IntervalCombination
+
+	// Used to replace FajrInterval or IshaInterval during parts of the year, e.g. Ramadan
+	SeasonalIntervals []SeasonalInterval
+
 	// The juristic method used to calculate Asr
 	Madhab AsrJuristicMethod
 
+	// Shadow length, as a multiple of the length of the object, used to calculate Asr in place of
+	// the one of the Madhab if greater than 0
+	AsrShadowFactor float64
+
+	// Shadow length, as a multiple of the length of the object, at which the time of Asr ends. Used
+	// to calculate AsrEnd if greater than 0
+	AsrEndShadowFactor float64
+
 	// Rules for placing bounds on Fajr and Isha for high latitude areas
 	HighLatitudeRule HighLatitudeRule
 
 	// Used to optionally add or subtract a set amount of time from each prayer time
 	Adjustments PrayerAdjustments
 
+	// Used to replace the angles or Adjustments during parts of the year
+	SeasonalOverrides []SeasonalOverride
+
 	// Used for method adjustments
 	MethodAdjustments PrayerAdjustments
 }
 
 func NewCalculationParametersBuilder() *CalculationParametersBuilder {
 	return &CalculationParametersBuilder{
-		Method:            OTHER,
-		FajrAngle:         0.0,
-		IshaAngle:         0.0,
-		IshaInterval:      0,
-		Madhab:            SHAFI_HANBALI_MALIKI,
-		HighLatitudeRule:  MIDDLE_OF_THE_NIGHT,
-		Adjustments:       PrayerAdjustments{},
-		MethodAdjustments: PrayerAdjustments{},
+		Method:                  OTHER,
+		FajrAngle:               0.0,
+		FajrInterval:            0,
+		FajrIntervalCombination: USE_INTERVAL,
+		IshaAngle:               0.0,
+		IshaInterval:            0,
+		IshaIntervalCombination: USE_INTERVAL,
+		SeasonalIntervals:       nil,
+		Madhab:                  SHAFI_HANBALI_MALIKI,
+		AsrShadowFactor:         0.0,
+		AsrEndShadowFactor:      0.0,
+		HighLatitudeRule:        MIDDLE_OF_THE_NIGHT,
+		Adjustments:             PrayerAdjustments{},
+		SeasonalOverrides:       nil,
+		MethodAdjustments:       PrayerAdjustments{},
 	}
 }
 
@@ -77,6 +128,16 @@ func (cpb *CalculationParametersBuilder) SetFajrAngle(fajrAngle float64) *Calcul
 	return cpb
 }
 
+func (cpb *CalculationParametersBuilder) SetFajrInterval(fajrInterval int) *CalculationParametersBuilder {
+	cpb.FajrInterval = fajrInterval
+	return cpb
+}
+
+func (cpb *CalculationParametersBuilder) SetFajrIntervalCombination(combination IntervalCombination) *CalculationParametersBuilder {
+	cpb.FajrIntervalCombination = combination
+	return cpb
+}
+
 func (cpb *CalculationParametersBuilder) SetIshaAngle(ishaAngle float64) *CalculationParametersBuilder {
 	cpb.IshaAngle = ishaAngle
 	return cpb
@@ -87,11 +148,31 @@ func (cpb *CalculationParametersBuilder) SetIshaInterval(ishaInterval int) *Calc
 	return cpb
 }
 
+func (cpb *CalculationParametersBuilder) SetIshaIntervalCombination(combination IntervalCombination) *CalculationParametersBuilder {
+	cpb.IshaIntervalCombination = combination
+	return cpb
+}
+
+func (cpb *CalculationParametersBuilder) SetSeasonalIntervals(seasonalIntervals ...SeasonalInterval) *CalculationParametersBuilder {
+	cpb.SeasonalIntervals = seasonalIntervals
+	return cpb
+}
+
 func (cpb *CalculationParametersBuilder) SetMadhab(madhab AsrJuristicMethod) *CalculationParametersBuilder {
 	cpb.Madhab = madhab
 	return cpb
 }
 
+func (cpb *CalculationParametersBuilder) SetAsrShadowFactor(asrShadowFactor float64) *CalculationParametersBuilder {
+	cpb.AsrShadowFactor = asrShadowFactor
+	return cpb
+}
+
+func (cpb *CalculationParametersBuilder) SetAsrEndShadowFactor(asrEndShadowFactor float64) *CalculationParametersBuilder {
+	cpb.AsrEndShadowFactor = asrEndShadowFactor
+	return cpb
+}
+
 func (cpb *CalculationParametersBuilder) SetHighLatitudeRule(highLatitudeRule HighLatitudeRule) *CalculationParametersBuilder {
 	cpb.HighLatitudeRule = highLatitudeRule
 	return cpb
@@ -102,6 +183,11 @@ func (cpb *CalculationParametersBuilder) SetAdjustments(adjustments PrayerAdjust
 	return cpb
 }
 
+func (cpb *CalculationParametersBuilder) SetSeasonalOverrides(seasonalOverrides ...SeasonalOverride) *CalculationParametersBuilder {
+	cpb.SeasonalOverrides = seasonalOverrides
+	return cpb
+}
+
 func (cpb *CalculationParametersBuilder) SetMethodAdjustments(methodAdjustments PrayerAdjustments) *CalculationParametersBuilder {
 	cpb.MethodAdjustments = methodAdjustments
 	return cpb
@@ -109,15 +195,63 @@ func (cpb *CalculationParametersBuilder) SetMethodAdjustments(methodAdjustments
 
 func (cpb *CalculationParametersBuilder) Build() *CalculationParameters {
 	return &CalculationParameters{
-		Method:            cpb.Method,
-		FajrAngle:         cpb.FajrAngle,
-		IshaAngle:         cpb.IshaAngle,
-		IshaInterval:      cpb.IshaInterval,
-		Madhab:            cpb.Madhab,
-		HighLatitudeRule:  cpb.HighLatitudeRule,
-		Adjustments:       cpb.Adjustments,
-		MethodAdjustments: cpb.MethodAdjustments,
+		Method:                  cpb.Method,
+		FajrAngle:               cpb.FajrAngle,
+		FajrInterval:            cpb.FajrInterval,
+		FajrIntervalCombination: cpb.FajrIntervalCombination,
+		IshaAngle:               cpb.IshaAngle,
+		IshaInterval:            cpb.IshaInterval,
+		IshaIntervalCombination: cpb.IshaIntervalCombination,
+		SeasonalIntervals:       append([]SeasonalInterval(nil), cpb.SeasonalIntervals...),
+		Madhab:                  cpb.Madhab,
+		AsrShadowFactor:         cpb.AsrShadowFactor,
+		AsrEndShadowFactor:      cpb.AsrEndShadowFactor,
+		HighLatitudeRule:        cpb.HighLatitudeRule,
+		Adjustments:             cpb.Adjustments,
+		SeasonalOverrides:       append([]SeasonalOverride(nil), cpb.SeasonalOverrides...),
+		MethodAdjustments:       cpb.MethodAdjustments,
+	}
+}
+
+// BuildValidated is Build, returning an error if the parameters are not valid. See
+// CalculationParameters.Validate.
+func (cpb *CalculationParametersBuilder) BuildValidated() (*CalculationParameters, error) {
+	params := cpb.Build()
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// Clone returns a copy of the parameters that shares no memory with them, so that either can be
+// changed without changing the other. It only allocates if there are seasonal intervals or
+// overrides.
+func (c CalculationParameters) Clone() CalculationParameters {
+	if len(c.SeasonalIntervals) > 0 {
+		c.SeasonalIntervals = append([]SeasonalInterval(nil), c.SeasonalIntervals...)
+	}
+	if len(c.SeasonalOverrides) > 0 {
+		overrides := make([]SeasonalOverride, len(c.SeasonalOverrides))
+		for i, o := range c.SeasonalOverrides {
+			if o.Adjustments != nil {
+				adjustments := *o.Adjustments
+				o.Adjustments = &adjustments
+			}
+			overrides[i] = o
+		}
+		c.SeasonalOverrides = overrides
+	}
+	return c
+}
+
+// ShadowFactor returns the shadow length, as a multiple of the length of the object, used to
+// calculate Asr: AsrShadowFactor if set, or the one of the Madhab otherwise.
+func (c *CalculationParameters) ShadowFactor() float64 {
+	if c.AsrShadowFactor > 0 {
+		return c.AsrShadowFactor
 	}
+	shadowLength, _ := c.Madhab.ShadowLength()
+	return shadowLength.Factor()
 }
 
 func (c *CalculationParameters) NightPortions() (*NightPortions, error) {
@@ -133,5 +267,5 @@ func (c *CalculationParameters) NightPortions() (*NightPortions, error) {
 		return NewNightPortions(c.FajrAngle/60.0, c.IshaAngle/60.0)
 	}
 
-	return nil, fmt.Errorf("invalid high latitude rule")
+	return nil, ErrInvalidHighLatitudeRule
 }
@@ -1,9 +1,12 @@
 package calc
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	util "github.com/mnadev/adhango/pkg/util"
 )
 
 func TestNightPortion(t *testing.T) {
@@ -35,3 +38,80 @@ func TestNightPortion(t *testing.T) {
 	assert.InDelta(t, 10.0/60.0, np.Fajr, 0.001)
 	assert.InDelta(t, 15.0/60.0, np.Isha, 0.001)
 }
+
+func TestValidate(t *testing.T) {
+	for method := OTHER + 1; method <= UOIF; method++ {
+		assert.Nil(t, GetMethodParameters(method).Validate())
+	}
+
+	_, err := NewCalculationParametersBuilder().BuildValidated()
+	assert.True(t, errors.Is(err, ErrInvalidParameters))
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Problems, 2)
+
+	params, err := NewCalculationParametersBuilder().
+		SetFajrAngle(18.0).
+		SetIshaInterval(90).
+		BuildValidated()
+	assert.Nil(t, err)
+	assert.Equal(t, 90, params.IshaInterval)
+
+	_, err = NewCalculationParametersBuilder().
+		SetFajrAngle(-18.0).
+		SetIshaAngle(17.0).
+		SetMadhab(AsrJuristicMethod(7)).
+		SetHighLatitudeRule(NO_HIGH_LATITUDE_RULE).
+		SetAdjustments(PrayerAdjustments{IshaAdj: 600}).
+		BuildValidated()
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Problems, 4)
+	assert.True(t, errors.Is(validationErr.Problems[2], ErrInvalidHighLatitudeRule))
+
+	params = GetMethodParameters(MUSLIM_WORLD_LEAGUE)
+	params.Madhab = AsrJuristicMethod(7)
+	params.AsrShadowFactor = 1.5
+	assert.Nil(t, params.Validate())
+	params.AsrEndShadowFactor = 1.5
+	assert.NotNil(t, params.Validate())
+}
+
+func TestMadhabShadowLength(t *testing.T) {
+	shadowLength, ok := SHAFI_HANBALI_MALIKI.ShadowLength()
+	assert.True(t, ok)
+	assert.Equal(t, util.SINGLE, shadowLength)
+
+	shadowLength, ok = HANAFI.ShadowLength()
+	assert.True(t, ok)
+	assert.Equal(t, util.DOUBLE, shadowLength)
+
+	_, ok = AsrJuristicMethod(7).ShadowLength()
+	assert.False(t, ok)
+
+	params := GetMethodParameters(NORTH_AMERICA)
+	params.Madhab = HANAFI
+	assert.Equal(t, 2.0, params.ShadowFactor())
+
+	// As before, an unknown madhab has the shadow length of SHAFI_HANBALI_MALIKI.
+	params.Madhab = AsrJuristicMethod(7)
+	assert.Equal(t, 1.0, params.ShadowFactor())
+}
+
+func TestClone(t *testing.T) {
+	params := GetMethodParameters(UMM_AL_QURA)
+	params.SeasonalOverrides = []SeasonalOverride{{
+		Calendar:    GREGORIAN,
+		From:        MonthDay{Month: 1, Day: 1},
+		To:          MonthDay{Month: 1, Day: 31},
+		Adjustments: &PrayerAdjustments{FajrAdj: 5},
+	}}
+	params.SeasonalIntervals = []SeasonalInterval{UmmAlQuraRamadanIshaInterval()}
+
+	cloned := params.Clone()
+	assert.Equal(t, *params, cloned)
+
+	cloned.SeasonalOverrides[0].Adjustments.FajrAdj = 10
+	cloned.SeasonalIntervals[0].Minutes = 30
+	assert.Equal(t, 5, params.SeasonalOverrides[0].Adjustments.FajrAdj)
+	assert.Equal(t, 120, params.SeasonalIntervals[0].Minutes)
+}
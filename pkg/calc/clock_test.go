@@ -0,0 +1,45 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2015, time.Month(9), 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	timer := clock.After(time.Minute)
+	clock.BlockUntil(1)
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-timer:
+		t.Fatal("timer fired before its deadline")
+	default:
+	}
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-timer)
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+
+	clock.Set(start)
+	assert.Equal(t, start, clock.Now())
+	assert.Equal(t, start, <-clock.After(0))
+}
+
+func TestFakeClockBlockUntil(t *testing.T) {
+	clock := NewFakeClock(time.Date(2015, time.Month(9), 1, 12, 0, 0, 0, time.UTC))
+
+	fired := make(chan time.Time)
+	go func() {
+		fired <- <-clock.After(time.Hour)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	assert.Equal(t, time.Date(2015, time.Month(9), 1, 13, 0, 0, 0, time.UTC), <-fired)
+}
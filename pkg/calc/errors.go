@@ -0,0 +1,55 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+var (
+	// The sun does not rise on the day, e.g. during polar night.
+	ErrNoSunrise = errors.New("the sun does not rise")
+
+	// The sun does not set on the day, e.g. during midnight sun.
+	ErrNoSunset = errors.New("the sun does not set")
+
+	// The time of solar transit cannot be calculated, e.g. for coordinates that are not finite.
+	ErrNoTransit = errors.New("the sun has no transit")
+
+	// The sun does not reach the altitude at which shadows have the length of Asr.
+	ErrNoAsr = errors.New("the sun never reaches the altitude of asr")
+
+	ErrInvalidHighLatitudeRule = errors.New("invalid high latitude rule")
+
+	ErrInvalidParameters = errors.New("invalid calculation parameters")
+
+	// A StaticSource has no prayer times for the date.
+	ErrNotInTimetable = errors.New("date is not in the timetable")
+)
+
+// PrayerError is returned by NewPrayerTimes when the time of Prayer cannot be calculated. Err is
+// one of ErrNoSunrise, ErrNoSunset, ErrNoTransit, ErrNoAsr, ErrInvalidHighLatitudeRule or
+// ErrInvalidParameters.
+type PrayerError struct {
+	Prayer Prayer
+	Err    error
+}
+
+func (e *PrayerError) Error() string {
+	return fmt.Sprintf("cannot calculate %v: %v", e.Prayer, e.Err)
+}
+
+func (e *PrayerError) Unwrap() error {
+	return e.Err
+}
+
+// noSunriseOrSunsetError returns ErrNoSunset if the sun is above the horizon at transit, and so
+// does not set, or ErrNoSunrise otherwise.
+func noSunriseOrSunsetError(s *util.SolarTime) error {
+	if 90-math.Abs(s.Observer.Latitude-s.Solar.Declination) > 0 {
+		return ErrNoSunset
+	}
+	return ErrNoSunrise
+}
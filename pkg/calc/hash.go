@@ -0,0 +1,120 @@
+package calc
+
+import "math"
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// Hash returns a 64-bit FNV-1a hash of every field of the parameters, so that equal parameters
+// have the same hash whichever pointer they are held by. It is meant for cache keys, e.g. by the
+// cache package, and is stable across processes.
+func (c *CalculationParameters) Hash() uint64 {
+	h := hasher(fnvOffset)
+	h.int(int64(c.Method))
+	h.float(c.FajrAngle)
+	h.int(int64(c.FajrInterval))
+	h.int(int64(c.FajrIntervalCombination))
+	h.float(c.IshaAngle)
+	h.int(int64(c.IshaInterval))
+	h.int(int64(c.IshaIntervalCombination))
+	h.int(int64(len(c.SeasonalIntervals)))
+	for _, i := range c.SeasonalIntervals {
+		h.int(int64(i.Prayer))
+		h.dateRange(i.Calendar, i.From, i.To)
+		h.int(int64(i.Minutes))
+	}
+	h.int(int64(c.Madhab))
+	h.float(c.AsrShadowFactor)
+	h.float(c.AsrEndShadowFactor)
+	h.int(int64(c.HighLatitudeRule))
+	h.adjustments(c.Adjustments)
+	h.int(int64(len(c.SeasonalOverrides)))
+	for _, o := range c.SeasonalOverrides {
+		h.dateRange(o.Calendar, o.From, o.To)
+		h.float(o.FajrAngle)
+		h.float(o.IshaAngle)
+		if o.Adjustments == nil {
+			h.int(0)
+		} else {
+			h.int(1)
+			h.adjustments(*o.Adjustments)
+		}
+	}
+	h.adjustments(c.MethodAdjustments)
+	return uint64(h)
+}
+
+// Equal reports whether the parameters are equal field by field, following the Adjustments of
+// seasonal overrides. It does not allocate, so that it can be used on every lookup of a cache.
+func (c *CalculationParameters) Equal(other *CalculationParameters) bool {
+	if c == other {
+		return true
+	}
+	if c == nil || other == nil {
+		return false
+	}
+	if c.Method != other.Method ||
+		c.FajrAngle != other.FajrAngle ||
+		c.FajrInterval != other.FajrInterval ||
+		c.FajrIntervalCombination != other.FajrIntervalCombination ||
+		c.IshaAngle != other.IshaAngle ||
+		c.IshaInterval != other.IshaInterval ||
+		c.IshaIntervalCombination != other.IshaIntervalCombination ||
+		c.Madhab != other.Madhab ||
+		c.AsrShadowFactor != other.AsrShadowFactor ||
+		c.AsrEndShadowFactor != other.AsrEndShadowFactor ||
+		c.HighLatitudeRule != other.HighLatitudeRule ||
+		c.Adjustments != other.Adjustments ||
+		c.MethodAdjustments != other.MethodAdjustments ||
+		len(c.SeasonalIntervals) != len(other.SeasonalIntervals) ||
+		len(c.SeasonalOverrides) != len(other.SeasonalOverrides) {
+		return false
+	}
+	for i := range c.SeasonalIntervals {
+		if c.SeasonalIntervals[i] != other.SeasonalIntervals[i] {
+			return false
+		}
+	}
+	for i, o := range c.SeasonalOverrides {
+		p := other.SeasonalOverrides[i]
+		if o.Calendar != p.Calendar || o.From != p.From || o.To != p.To || o.FajrAngle != p.FajrAngle || o.IshaAngle != p.IshaAngle {
+			return false
+		}
+		if (o.Adjustments == nil) != (p.Adjustments == nil) || (o.Adjustments != nil && *o.Adjustments != *p.Adjustments) {
+			return false
+		}
+	}
+	return true
+}
+
+type hasher uint64
+
+func (h *hasher) int(v int64) {
+	for i := 0; i < 8; i++ {
+		*h ^= hasher(byte(v >> (8 * i)))
+		*h *= fnvPrime
+	}
+}
+
+func (h *hasher) float(v float64) {
+	h.int(int64(math.Float64bits(v)))
+}
+
+func (h *hasher) dateRange(calendar Calendar, from MonthDay, to MonthDay) {
+	h.int(int64(calendar))
+	h.int(int64(from.Month))
+	h.int(int64(from.Day))
+	h.int(int64(to.Month))
+	h.int(int64(to.Day))
+}
+
+func (h *hasher) adjustments(a PrayerAdjustments) {
+	h.int(int64(a.FajrAdj))
+	h.int(int64(a.SunriseAdj))
+	h.int(int64(a.DhuhrAdj))
+	h.int(int64(a.AsrAdj))
+	h.int(int64(a.MaghribAdj))
+	h.int(int64(a.IshaAdj))
+}
@@ -0,0 +1,70 @@
+package calc
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashEqualParameters(t *testing.T) {
+	assert.Equal(t, GetMethodParameters(MUSLIM_WORLD_LEAGUE).Hash(), GetMethodParameters(MUSLIM_WORLD_LEAGUE).Hash())
+	assert.NotEqual(t, GetMethodParameters(MUSLIM_WORLD_LEAGUE).Hash(), GetMethodParameters(EGYPTIAN).Hash())
+
+	a := GetMethodParameters(UMM_AL_QURA)
+	a.SeasonalOverrides = []SeasonalOverride{{From: MonthDay{12, 1}, To: MonthDay{2, 28}, Adjustments: &PrayerAdjustments{IshaAdj: 5}}}
+	b := GetMethodParameters(UMM_AL_QURA)
+	b.SeasonalOverrides = []SeasonalOverride{{From: MonthDay{12, 1}, To: MonthDay{2, 28}, Adjustments: &PrayerAdjustments{IshaAdj: 5}}}
+	assert.Equal(t, a.Hash(), b.Hash())
+	assert.True(t, a.Equal(b))
+
+	b.SeasonalOverrides[0].Adjustments.IshaAdj = 6
+	assert.NotEqual(t, a.Hash(), b.Hash())
+	assert.False(t, a.Equal(b))
+	b.SeasonalOverrides[0].Adjustments = nil
+	assert.NotEqual(t, a.Hash(), b.Hash())
+	assert.False(t, a.Equal(b))
+	assert.False(t, b.Equal(a))
+
+	b = GetMethodParameters(UMM_AL_QURA)
+	b.SeasonalIntervals = []SeasonalInterval{UmmAlQuraRamadanIshaInterval()}
+	c := GetMethodParameters(UMM_AL_QURA)
+	c.SeasonalIntervals = []SeasonalInterval{UmmAlQuraRamadanIshaInterval()}
+	assert.Equal(t, b.Hash(), c.Hash())
+	assert.True(t, b.Equal(c))
+	c.SeasonalIntervals[0].Minutes = 90
+	assert.NotEqual(t, b.Hash(), c.Hash())
+	assert.False(t, b.Equal(c))
+
+	assert.False(t, b.Equal(nil))
+	cloned := a.Clone()
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = a.Equal(&cloned)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+// Every field must be part of the hash and of Equal, or parameters that calculate different times
+// could share cache entries.
+func TestHashCoversEveryField(t *testing.T) {
+	base := GetMethodParameters(MUSLIM_WORLD_LEAGUE)
+	fields := reflect.TypeOf(*base)
+	for i := 0; i < fields.NumField(); i++ {
+		changed := *base
+		field := reflect.ValueOf(&changed).Elem().Field(i)
+		switch field.Kind() {
+		case reflect.Int, reflect.Int64:
+			field.SetInt(field.Int() + 1)
+		case reflect.Float64:
+			field.SetFloat(field.Float() + 1)
+		case reflect.Slice:
+			field.Set(reflect.Append(field, reflect.Zero(field.Type().Elem())))
+		case reflect.Struct:
+			field.Field(0).SetInt(field.Field(0).Int() + 1)
+		default:
+			t.Fatalf("unhandled kind %v of %s", field.Kind(), fields.Field(i).Name)
+		}
+		assert.NotEqual(t, base.Hash(), changed.Hash(), fields.Field(i).Name)
+		assert.False(t, base.Equal(&changed), fields.Field(i).Name)
+	}
+}
@@ -1,5 +1,10 @@
 package calc
 
+import (
+	"fmt"
+	"strings"
+)
+
 type HighLatitudeRule int64
 
 const (
@@ -17,3 +22,19 @@ const (
 	// is fajrAngle / 60 and ishaAngle/60.
 	TWILIGHT_ANGLE
 )
+
+// ParseHighLatitudeRule returns the rule named `name`, the name of its constant in any case, e.g.
+// "SEVENTH_OF_THE_NIGHT". An error wrapping ErrInvalidParameters is returned for other names.
+func ParseHighLatitudeRule(name string) (HighLatitudeRule, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "NO_HIGH_LATITUDE_RULE":
+		return NO_HIGH_LATITUDE_RULE, nil
+	case "MIDDLE_OF_THE_NIGHT":
+		return MIDDLE_OF_THE_NIGHT, nil
+	case "SEVENTH_OF_THE_NIGHT":
+		return SEVENTH_OF_THE_NIGHT, nil
+	case "TWILIGHT_ANGLE":
+		return TWILIGHT_ANGLE, nil
+	}
+	return NO_HIGH_LATITUDE_RULE, fmt.Errorf("%w: unknown high latitude rule %q", ErrInvalidParameters, name)
+}
@@ -0,0 +1,39 @@
+package calc
+
+import (
+	"time"
+
+	data "github.com/mnadev/adhango/pkg/data"
+)
+
+// JumuahSession is one of the Friday congregations held by a mosque, with the khutbah and iqamah
+// given as local clock times.
+type JumuahSession struct {
+	Khutbah data.TimeComponents
+	Iqamah  data.TimeComponents
+}
+
+// JumuahTimes are the times of a JumuahSession on a particular Friday.
+type JumuahTimes struct {
+	Khutbah time.Time
+	Iqamah  time.Time
+}
+
+// JumuahTimes returns the khutbah and iqamah times of each of `sessions` in the time zone of `p`,
+// or nil if the date of `p` is not a Friday.
+func (p *PrayerTimes) JumuahTimes(sessions []JumuahSession) []JumuahTimes {
+	if !p.IsFriday() {
+		return nil
+	}
+
+	d := p.DateComponent
+	loc := p.Dhuhr.Location()
+	times := make([]JumuahTimes, 0, len(sessions))
+	for _, session := range sessions {
+		times = append(times, JumuahTimes{
+			Khutbah: time.Date(d.Year, time.Month(d.Month), d.Day, session.Khutbah.Hours, session.Khutbah.Minutes, session.Khutbah.Seconds, 0, loc),
+			Iqamah:  time.Date(d.Year, time.Month(d.Month), d.Day, session.Iqamah.Hours, session.Iqamah.Minutes, session.Iqamah.Seconds, 0, loc),
+		})
+	}
+	return times
+}
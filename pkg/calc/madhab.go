@@ -1,6 +1,9 @@
 package calc
 
 import (
+	"fmt"
+	"strings"
+
 	util "github.com/mnadev/adhango/pkg/util"
 )
 
@@ -12,7 +15,33 @@ const (
 	HANAFI
 )
 
+// Deprecated: MadhabToShadowLengthMap can be modified by any package, which changes how every
+// prayer time is calculated. Use AsrJuristicMethod.ShadowLength instead, which is what the
+// calculations use.
 var MadhabToShadowLengthMap = map[AsrJuristicMethod]util.ShadowLength{
 	SHAFI_HANBALI_MALIKI: util.SINGLE,
 	HANAFI:               util.DOUBLE,
 }
+
+// ShadowLength returns the shadow length of the madhab, or false if the madhab is unknown.
+func (m AsrJuristicMethod) ShadowLength() (util.ShadowLength, bool) {
+	switch m {
+	case SHAFI_HANBALI_MALIKI:
+		return util.SINGLE, true
+	case HANAFI:
+		return util.DOUBLE, true
+	}
+	return util.SINGLE, false
+}
+
+// ParseMadhab returns the madhab named `name` in any case: "SHAFI" or "SHAFI_HANBALI_MALIKI", or
+// "HANAFI". An error wrapping ErrInvalidParameters is returned for other names.
+func ParseMadhab(name string) (AsrJuristicMethod, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "SHAFI", "SHAFI_HANBALI_MALIKI":
+		return SHAFI_HANBALI_MALIKI, nil
+	case "HANAFI":
+		return HANAFI, nil
+	}
+	return SHAFI_HANBALI_MALIKI, fmt.Errorf("%w: unknown madhab %q", ErrInvalidParameters, name)
+}
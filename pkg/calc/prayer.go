@@ -16,4 +16,27 @@ const (
 	MAGHRIB
 
 	ISHA
+
+	// The Friday congregational prayer, which takes the place of Dhuhr on Fridays.
+	JUMUAH
 )
+
+func (p Prayer) String() string {
+	switch p {
+	case FAJR:
+		return "Fajr"
+	case SUNRISE:
+		return "Sunrise"
+	case DHUHR:
+		return "Dhuhr"
+	case ASR:
+		return "Asr"
+	case MAGHRIB:
+		return "Maghrib"
+	case ISHA:
+		return "Isha"
+	case JUMUAH:
+		return "Jumuah"
+	}
+	return "NoPrayer"
+}
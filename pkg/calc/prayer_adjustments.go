@@ -8,3 +8,15 @@ type PrayerAdjustments struct {
 	MaghribAdj int
 	IshaAdj    int
 }
+
+// Add returns the sum of `a` and `b` for each prayer.
+func (a PrayerAdjustments) Add(b PrayerAdjustments) PrayerAdjustments {
+	return PrayerAdjustments{
+		FajrAdj:    a.FajrAdj + b.FajrAdj,
+		SunriseAdj: a.SunriseAdj + b.SunriseAdj,
+		DhuhrAdj:   a.DhuhrAdj + b.DhuhrAdj,
+		AsrAdj:     a.AsrAdj + b.AsrAdj,
+		MaghribAdj: a.MaghribAdj + b.MaghribAdj,
+		IshaAdj:    a.IshaAdj + b.IshaAdj,
+	}
+}
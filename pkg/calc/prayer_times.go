@@ -1,6 +1,7 @@
 package calc
 
 import (
+	"fmt"
 	"math"
 	"time"
 
@@ -18,134 +19,352 @@ type PrayerTimes struct {
 	Coords            *util.Coordinates
 	DateComponent     *data.DateComponents
 	CalculationParams *CalculationParameters
+
+	// The unrounded, unadjusted times the prayer times were derived from.
+	Details *PrayerTimesDetails
+
+	// The end of the time of Asr, when shadows reach the AsrEndShadowFactor of the
+	// CalculationParameters. The zero time if AsrEndShadowFactor is not set, or the shadows never
+	// reach it before sunset.
+	AsrEnd time.Time
+
+	clock Clock
+
+	// The source the prayer times came from, used for adjacent days. If nil, adjacent days are
+	// calculated with NewPrayerTimes.
+	source TimetableSource
 }
 
 func NewPrayerTimes(coords *util.Coordinates, date *data.DateComponents, params *CalculationParameters) (*PrayerTimes, error) {
-	prayerDate := data.ResolveTimeByDateComponents(date)
-	dayOfYear := prayerDate.YearDay()
+	return newPrayerTimes(coords, date, params, nil)
+}
+
+// CalculatePrayerTimes is NewPrayerTimes taking its arguments by value, e.g. the parameters
+// returned by MethodParameters. The PrayerTimes hold their own copy of the parameters, so the
+// same parameters can be shared by any number of goroutines without being changed by any of them.
+func CalculatePrayerTimes(coords util.Coordinates, date data.DateComponents, params CalculationParameters) (*PrayerTimes, error) {
+	params = params.Clone()
+	return newPrayerTimes(&coords, &date, &params, nil)
+}
 
-	tomorrowDate := prayerDate.AddDate(0, 0, 1)
-	tomorrow := data.NewDateComponents(tomorrowDate)
+// newPrayerTimes calculates the prayer times, recording how each was derived into `trace` if it is
+// not nil.
+func newPrayerTimes(coords *util.Coordinates, date *data.DateComponents, params *CalculationParameters, trace *CalculationTrace) (*PrayerTimes, error) {
+	if coords == nil || date == nil || params == nil {
+		return nil, fmt.Errorf("%w: coordinates, date and parameters must not be nil", ErrInvalidParameters)
+	}
 
-	solarTime := util.NewSolarTime(date, coords)
+	tomorrow := data.NewDateComponents(data.ResolveTimeByDateComponents(date).AddDate(0, 0, 1))
+	solarTime, tomorrowSolarTime := solarTimes(date, tomorrow, coords)
+	return prayerTimesFromSolarTimes(coords, date, params, solarTime, tomorrowSolarTime, trace)
+}
 
-	timeComponents, err := data.NewTimeComponents(solarTime.Transit)
+// prayerTimesFromSolarTimes calculates the prayer times from the solar times of `date` and of the
+// following day at `coords`, which can be shared between calculations.
+func prayerTimesFromSolarTimes(coords *util.Coordinates, date *data.DateComponents, params *CalculationParameters, solarTime *util.SolarTime, tomorrowSolarTime *util.SolarTime, trace *CalculationTrace) (*PrayerTimes, error) {
+	day, err := newSolarDay(date, solarTime, tomorrowSolarTime)
 	if err != nil {
 		return nil, err
 	}
-	transit := timeComponents.DateComponents(date)
+	return prayerTimesFromSolarDay(coords, date, params, day, trace)
+}
+
+func solarTimes(date *data.DateComponents, tomorrow *data.DateComponents, coords *util.Coordinates) (*util.SolarTime, *util.SolarTime) {
+	return util.NewSolarTime(date, coords), util.NewSolarTime(tomorrow, coords)
+}
+
+// solarDay holds the times of a day that depend only on the position of the sun, and so can be
+// shared by all parameters.
+type solarDay struct {
+	solarTime *util.SolarTime
+
+	transit time.Time
+	sunrise time.Time
+	sunset  time.Time
+
+	// From sunset to sunrise the following day
+	night time.Duration
+}
+
+func newSolarDay(date *data.DateComponents, solarTime *util.SolarTime, tomorrowSolarTime *util.SolarTime) (*solarDay, error) {
+	tomorrow := data.NewDateComponents(data.ResolveTimeByDateComponents(date).AddDate(0, 0, 1))
+
+	day := &solarDay{solarTime: solarTime}
+
+	timeComponents, err := data.NewTimeComponents(solarTime.Transit)
+	if err != nil {
+		return nil, &PrayerError{Prayer: DHUHR, Err: ErrNoTransit}
+	}
+	day.transit = timeComponents.DateComponents(date)
 
 	timeComponents, err = data.NewTimeComponents(solarTime.Sunrise)
 	if err != nil {
-		return nil, err
+		return nil, &PrayerError{Prayer: SUNRISE, Err: noSunriseOrSunsetError(solarTime)}
 	}
-	sunriseComponents := timeComponents.DateComponents(date)
+	day.sunrise = timeComponents.DateComponents(date)
 
 	timeComponents, err = data.NewTimeComponents(solarTime.Sunset)
 	if err != nil {
-		return nil, err
+		return nil, &PrayerError{Prayer: MAGHRIB, Err: noSunriseOrSunsetError(solarTime)}
 	}
-	sunsetComponents := timeComponents.DateComponents(date)
+	day.sunset = timeComponents.DateComponents(date)
 
-	tomorrowSolarTime := util.NewSolarTime(tomorrow, coords)
-	tomorrowSunriseComponents, err := data.NewTimeComponents(tomorrowSolarTime.Sunrise)
+	// The night ends at sunrise the following day, so Fajr and Isha cannot be calculated without it.
+	timeComponents, err = data.NewTimeComponents(tomorrowSolarTime.Sunrise)
 	if err != nil {
-		return nil, err
+		return nil, &PrayerError{Prayer: FAJR, Err: noSunriseOrSunsetError(tomorrowSolarTime)}
 	}
+	day.night = timeComponents.DateComponents(tomorrow).Sub(day.sunset)
+	return day, nil
+}
+
+// prayerTimesFromSolarDay calculates the prayer times of `date` at `coords` from `day`.
+func prayerTimesFromSolarDay(coords *util.Coordinates, date *data.DateComponents, params *CalculationParameters, day *solarDay, trace *CalculationTrace) (*PrayerTimes, error) {
+	// The returned PrayerTimes keep the parameters they were called with, while the calculation uses
+	// those in effect on the date.
+	calculationParams := params
+	params = params.ForDate(date)
 
-	tempDhuhr := transit
-	tempSunrise := sunriseComponents
-	tempMaghrib := sunsetComponents
+	dayOfYear := data.ResolveTimeByDateComponents(date).YearDay()
+	solarTime := day.solarTime
 
-	timeComponents, err = data.NewTimeComponents(solarTime.Afternoon(MadhabToShadowLengthMap[params.Madhab]))
+	sunriseComponents := day.sunrise
+	sunsetComponents := day.sunset
+	tempDhuhr := day.transit
+	tempSunrise := day.sunrise
+	tempMaghrib := day.sunset
+
+	timeComponents, err := data.NewTimeComponents(solarTime.AfternoonWithShadowFactor(params.ShadowFactor()))
 	if err != nil {
-		return nil, err
+		return nil, &PrayerError{Prayer: ASR, Err: ErrNoAsr}
 	}
 	tempAsr := timeComponents.DateComponents(date)
 
-	tomorrowSunrise := tomorrowSunriseComponents.DateComponents(tomorrow)
-	night := tomorrowSunrise.Sub(sunsetComponents) * 1000
-
-	tempFajr := time.Time{}
-	timeComponents, err = data.NewTimeComponents(solarTime.HourAngle(-1*params.FajrAngle, false))
-	if err == nil {
-		tempFajr = timeComponents.DateComponents(date)
+	tempAsrEnd := time.Time{}
+	if params.AsrEndShadowFactor > 0 {
+		timeComponents, err = data.NewTimeComponents(solarTime.AfternoonWithShadowFactor(params.AsrEndShadowFactor))
+		if err == nil {
+			tempAsrEnd = timeComponents.DateComponents(date)
+		}
 	}
 
-	if params.Method == MOON_SIGHTING_COMMITTEE && coords.Latitude >= 55 {
-		tempFajr = sunriseComponents.Add(time.Second * time.Duration(-1*(int)(night.Seconds()/7000)))
+	if trace != nil {
+		for _, solar := range []struct {
+			prayer Prayer
+			time   time.Time
+			reason string
+		}{
+			{SUNRISE, tempSunrise, "sun reaches the horizon"},
+			{DHUHR, tempDhuhr, "solar transit"},
+			{ASR, tempAsr, "shadow length of the madhab"},
+			{MAGHRIB, tempMaghrib, "sun reaches the horizon"},
+		} {
+			trace.addCandidate(solar.prayer, SOLAR_POSITION, solar.time)
+			trace.choose(solar.prayer, SOLAR_POSITION, solar.time, solar.reason)
+		}
 	}
 
+	night := day.night * 1000
+
 	nightPortions, err := params.NightPortions()
 	if err != nil {
-		return nil, err
+		return nil, &PrayerError{Prayer: FAJR, Err: err}
 	}
+	if trace != nil {
+		trace.Night = night / 1000
+		trace.NightPortions = *nightPortions
+	}
+
+	fajrInterval, ishaInterval := params.intervals(date)
 
-	safeFajr := time.Time{}
-	if params.Method == MOON_SIGHTING_COMMITTEE {
-		safeFajr = SeasonAdjustedMorningTwilight(coords.Latitude, dayOfYear, date.Year, sunriseComponents)
+	// Fajr calculation with check against safe value
+	tempFajr := time.Time{}
+	fajrSource := INTERVAL
+	if fajrInterval > 0 && params.FajrIntervalCombination == USE_INTERVAL {
+		tempFajr = tempSunrise.Add(time.Second * time.Duration(-1*fajrInterval*60))
+		trace.addCandidate(FAJR, INTERVAL, tempFajr)
+		trace.choose(FAJR, INTERVAL, tempFajr, "FajrInterval is set")
 	} else {
-		portion := nightPortions.Fajr
-		nightFraction := (int64)(portion * night.Seconds() / 1000)
-		safeFajr = sunriseComponents.Add(time.Second * time.Duration(-1*(int)(nightFraction)))
-	}
+		fajrSource = ANGLE_BASED
+		timeComponents, err = data.NewTimeComponents(solarTime.HourAngle(-1*params.FajrAngle, false))
+		if err == nil {
+			tempFajr = timeComponents.DateComponents(date)
+		}
+		trace.addCandidate(FAJR, ANGLE_BASED, tempFajr)
+
+		if params.Method == MOON_SIGHTING_COMMITTEE && coords.Latitude >= 55 {
+			tempFajr = sunriseComponents.Add(time.Second * time.Duration(-1*(int)(night.Seconds()/7000)))
+			fajrSource = SEVENTH_OF_NIGHT_ABOVE_55
+			trace.addCandidate(FAJR, SEVENTH_OF_NIGHT_ABOVE_55, tempFajr)
+		}
 
-	if tempFajr.IsZero() || tempFajr.Before(safeFajr) {
-		tempFajr = safeFajr
+		safeFajr := time.Time{}
+		safeFajrSource := HIGH_LATITUDE_RULE
+		if params.Method == MOON_SIGHTING_COMMITTEE {
+			safeFajr = SeasonAdjustedMorningTwilight(coords.Latitude, dayOfYear, date.Year, sunriseComponents)
+			safeFajrSource = SEASONAL
+		} else {
+			portion := nightPortions.Fajr
+			nightFraction := (int64)(portion * night.Seconds() / 1000)
+			safeFajr = sunriseComponents.Add(time.Second * time.Duration(-1*(int)(nightFraction)))
+		}
+		trace.addCandidate(FAJR, safeFajrSource, safeFajr)
+
+		if tempFajr.IsZero() {
+			tempFajr = safeFajr
+			fajrSource = safeFajrSource
+			trace.choose(FAJR, safeFajrSource, tempFajr, "the sun does not reach the Fajr angle")
+		} else if tempFajr.Before(safeFajr) {
+			tempFajr = safeFajr
+			fajrSource = safeFajrSource
+			trace.choose(FAJR, safeFajrSource, tempFajr, "calculated time is earlier than the safe limit")
+		} else {
+			trace.choose(FAJR, fajrSource, tempFajr, "within the safe limit")
+		}
+
+		if fajrInterval > 0 {
+			intervalFajr := tempSunrise.Add(time.Second * time.Duration(-1*fajrInterval*60))
+			trace.addCandidate(FAJR, INTERVAL, intervalFajr)
+			if (params.FajrIntervalCombination == EARLIER_OF_ANGLE_AND_INTERVAL && intervalFajr.Before(tempFajr)) ||
+				(params.FajrIntervalCombination == LATER_OF_ANGLE_AND_INTERVAL && intervalFajr.After(tempFajr)) {
+				tempFajr = intervalFajr
+				fajrSource = INTERVAL
+				trace.choose(FAJR, INTERVAL, tempFajr, "interval based time is chosen by the FajrIntervalCombination")
+			}
+		}
 	}
 
 	// Isha calculation with check against safe value
 	tempIsha := time.Time{}
-	if params.IshaInterval > 0 {
-		tempIsha = tempMaghrib.Add(time.Second * time.Duration(params.IshaInterval*60))
+	ishaSource := INTERVAL
+	if ishaInterval > 0 && params.IshaIntervalCombination == USE_INTERVAL {
+		tempIsha = tempMaghrib.Add(time.Second * time.Duration(ishaInterval*60))
+		trace.addCandidate(ISHA, INTERVAL, tempIsha)
+		trace.choose(ISHA, INTERVAL, tempIsha, "IshaInterval is set")
 	} else {
+		ishaSource = ANGLE_BASED
 		timeComponents, err = data.NewTimeComponents(solarTime.HourAngle(-1*params.IshaAngle, true))
 		if err == nil {
 			tempIsha = timeComponents.DateComponents(date)
 		}
+		trace.addCandidate(ISHA, ANGLE_BASED, tempIsha)
 
 		if params.Method == MOON_SIGHTING_COMMITTEE && coords.Latitude >= 55 {
 			nightFraction := int64(night.Seconds() / 7000)
 			tempIsha = sunsetComponents.Add(time.Second * time.Duration(nightFraction))
+			ishaSource = SEVENTH_OF_NIGHT_ABOVE_55
+			trace.addCandidate(ISHA, SEVENTH_OF_NIGHT_ABOVE_55, tempIsha)
 		}
 
 		safeIsha := time.Time{}
+		safeIshaSource := HIGH_LATITUDE_RULE
 		if params.Method == MOON_SIGHTING_COMMITTEE {
 			safeIsha = SeasonAdjustedEveningTwilight(coords.Latitude, dayOfYear, date.Year, sunsetComponents)
+			safeIshaSource = SEASONAL
 		} else {
 			portion := nightPortions.Isha
 			nightFraction := int64(portion * night.Seconds() / 1000)
 			safeIsha = sunsetComponents.Add(time.Second * time.Duration(nightFraction))
 		}
+		trace.addCandidate(ISHA, safeIshaSource, safeIsha)
 
-		if tempIsha.IsZero() || tempIsha.After(safeIsha) {
+		if tempIsha.IsZero() {
+			tempIsha = safeIsha
+			ishaSource = safeIshaSource
+			trace.choose(ISHA, safeIshaSource, tempIsha, "the sun does not reach the Isha angle")
+		} else if tempIsha.After(safeIsha) {
 			tempIsha = safeIsha
+			ishaSource = safeIshaSource
+			trace.choose(ISHA, safeIshaSource, tempIsha, "calculated time is later than the safe limit")
+		} else {
+			trace.choose(ISHA, ishaSource, tempIsha, "within the safe limit")
+		}
+
+		if ishaInterval > 0 {
+			intervalIsha := tempMaghrib.Add(time.Second * time.Duration(ishaInterval*60))
+			trace.addCandidate(ISHA, INTERVAL, intervalIsha)
+			if (params.IshaIntervalCombination == EARLIER_OF_ANGLE_AND_INTERVAL && intervalIsha.Before(tempIsha)) ||
+				(params.IshaIntervalCombination == LATER_OF_ANGLE_AND_INTERVAL && intervalIsha.After(tempIsha)) {
+				tempIsha = intervalIsha
+				ishaSource = INTERVAL
+				trace.choose(ISHA, INTERVAL, tempIsha, "interval based time is chosen by the IshaIntervalCombination")
+			}
 		}
 	}
 
 	// Assign final times to public struct members with all offsets
-	fajr := data.RoundToNearestMinute(tempFajr.Add(time.Minute * time.Duration(params.Adjustments.FajrAdj+params.MethodAdjustments.FajrAdj)))
-	sunrise := data.RoundToNearestMinute(tempSunrise.Add(time.Minute * time.Duration(params.Adjustments.SunriseAdj+params.MethodAdjustments.SunriseAdj)))
-	dhuhr := data.RoundToNearestMinute(tempDhuhr.Add(time.Minute * time.Duration(params.Adjustments.DhuhrAdj+params.MethodAdjustments.DhuhrAdj)))
-	asr := data.RoundToNearestMinute(tempAsr.Add(time.Minute * time.Duration(params.Adjustments.AsrAdj+params.MethodAdjustments.AsrAdj)))
-	maghrib := data.RoundToNearestMinute(tempMaghrib.Add(time.Minute * time.Duration(params.Adjustments.MaghribAdj+params.MethodAdjustments.MaghribAdj)))
-	isha := data.RoundToNearestMinute(tempIsha.Add(time.Minute * time.Duration(params.Adjustments.IshaAdj+params.MethodAdjustments.IshaAdj)))
-
-	return &PrayerTimes{
+	adjustments := params.Adjustments.Add(params.MethodAdjustments)
+	fajr := data.RoundToNearestMinute(tempFajr.Add(time.Minute * time.Duration(adjustments.FajrAdj)))
+	sunrise := data.RoundToNearestMinute(tempSunrise.Add(time.Minute * time.Duration(adjustments.SunriseAdj)))
+	dhuhr := data.RoundToNearestMinute(tempDhuhr.Add(time.Minute * time.Duration(adjustments.DhuhrAdj)))
+	asr := data.RoundToNearestMinute(tempAsr.Add(time.Minute * time.Duration(adjustments.AsrAdj)))
+	maghrib := data.RoundToNearestMinute(tempMaghrib.Add(time.Minute * time.Duration(adjustments.MaghribAdj)))
+	isha := data.RoundToNearestMinute(tempIsha.Add(time.Minute * time.Duration(adjustments.IshaAdj)))
+	asrEnd := time.Time{}
+	if !tempAsrEnd.IsZero() {
+		asrEnd = data.RoundToNearestMinute(tempAsrEnd)
+	}
+
+	prayerTimes := &PrayerTimes{
 		Fajr:              fajr,
 		Sunrise:           sunrise,
 		Dhuhr:             dhuhr,
 		Asr:               asr,
 		Maghrib:           maghrib,
 		Isha:              isha,
+		AsrEnd:            asrEnd,
 		Coords:            coords,
 		DateComponent:     date,
-		CalculationParams: params,
-	}, nil
+		CalculationParams: calculationParams,
+		Details: &PrayerTimesDetails{
+			Fajr:        tempFajr,
+			Sunrise:     tempSunrise,
+			Dhuhr:       tempDhuhr,
+			Asr:         tempAsr,
+			Maghrib:     tempMaghrib,
+			Isha:        tempIsha,
+			AsrEnd:      tempAsrEnd,
+			Adjustments: adjustments,
+		},
+	}
+	prayerTimes.Details.setSources(fajrSource, ishaSource)
+
+	if trace != nil {
+		for _, p := range trace.Prayers {
+			trace.finish(p.Prayer, prayerTimes.Details.AdjustmentForPrayer(p.Prayer), prayerTimes.TimeForPrayer(p.Prayer))
+		}
+	}
+	return prayerTimes, nil
+}
+
+// SourceForPrayer returns how the time of `prayer` was derived, e.g. ANGLE_BASED or
+// HIGH_LATITUDE_RULE when the angle based time was replaced by the high latitude safe limit.
+func (p *PrayerTimes) SourceForPrayer(prayer Prayer) TimeSource {
+	return p.Details.SourceForPrayer(prayer)
+}
+
+// SetClock sets the Clock used by the methods that work relative to the current time, such as
+// CurrentPrayerNow and NextPrayerNow. The system clock is used if no Clock has been set.
+func (p *PrayerTimes) SetClock(clock Clock) {
+	p.clock = clock
+}
+
+// SetSource sets the TimetableSource the prayer times of adjacent days are taken from, e.g. by
+// TimeUntilNextPrayer. It is meant for implementations of TimetableSource that wrap another, such
+// as a cache. Adjacent days are calculated with NewPrayerTimes if no source has been set.
+func (p *PrayerTimes) SetSource(source TimetableSource) {
+	p.source = source
+}
+
+func (p *PrayerTimes) now() time.Time {
+	if p.clock == nil {
+		return time.Now().UTC()
+	}
+	return p.clock.Now().UTC()
 }
 
 func (p *PrayerTimes) CurrentPrayerNow() Prayer {
-	return p.CurrentPrayer(time.Now().UTC())
+	return p.CurrentPrayer(p.now())
 }
 
 func (p *PrayerTimes) CurrentPrayer(t time.Time) Prayer {
@@ -156,7 +375,7 @@ func (p *PrayerTimes) CurrentPrayer(t time.Time) Prayer {
 	} else if p.Asr.Unix()-t.Unix() <= 0 {
 		return ASR
 	} else if p.Dhuhr.Unix()-t.Unix() <= 0 {
-		return DHUHR
+		return p.dhuhrOrJumuah()
 	} else if p.Sunrise.Unix()-t.Unix() <= 0 {
 		return SUNRISE
 	} else if p.Fajr.Unix()-t.Unix() <= 0 {
@@ -167,7 +386,7 @@ func (p *PrayerTimes) CurrentPrayer(t time.Time) Prayer {
 }
 
 func (p *PrayerTimes) NextPrayerNow() Prayer {
-	return p.NextPrayer(time.Now().UTC())
+	return p.NextPrayer(p.now())
 }
 
 func (p *PrayerTimes) NextPrayer(t time.Time) Prayer {
@@ -180,7 +399,7 @@ func (p *PrayerTimes) NextPrayer(t time.Time) Prayer {
 	} else if p.Dhuhr.Unix()-t.Unix() <= 0 {
 		return ASR
 	} else if p.Sunrise.Unix()-t.Unix() <= 0 {
-		return DHUHR
+		return p.dhuhrOrJumuah()
 	} else if p.Fajr.Unix()-t.Unix() <= 0 {
 		return SUNRISE
 	} else {
@@ -202,6 +421,10 @@ func (p *PrayerTimes) TimeForPrayer(prayer Prayer) time.Time {
 		return p.Maghrib
 	case ISHA:
 		return p.Isha
+	case JUMUAH:
+		if p.IsFriday() {
+			return p.Dhuhr
+		}
 	case NO_PRAYER:
 	default:
 		break
@@ -209,6 +432,78 @@ func (p *PrayerTimes) TimeForPrayer(prayer Prayer) time.Time {
 	return time.Time{}
 }
 
+// IsFriday returns true if the date of `p` is a Friday. On Fridays CurrentPrayer and NextPrayer
+// return JUMUAH in place of DHUHR. It returns false if `p` has no DateComponent.
+func (p *PrayerTimes) IsFriday() bool {
+	if p.DateComponent == nil {
+		return false
+	}
+	return data.ResolveTimeByDateComponents(p.DateComponent).Weekday() == time.Friday
+}
+
+func (p *PrayerTimes) dhuhrOrJumuah() Prayer {
+	if p.IsFriday() {
+		return JUMUAH
+	}
+	return DHUHR
+}
+
+// TimeUntilNextPrayer returns the prayer following `t` and the time remaining until it begins.
+// After Isha, the next prayer is the following day's Fajr.
+func (p *PrayerTimes) TimeUntilNextPrayer(t time.Time) (Prayer, time.Duration, error) {
+	next := p.NextPrayer(t)
+	if next != NO_PRAYER {
+		return next, p.TimeForPrayer(next).Sub(t), nil
+	}
+
+	tomorrow, err := p.adjacentDay(1)
+	if err != nil {
+		return NO_PRAYER, 0, err
+	}
+	return FAJR, tomorrow.Fajr.Sub(t), nil
+}
+
+// TimeUntilNextPrayerNow is TimeUntilNextPrayer evaluated at the current time of the Clock.
+func (p *PrayerTimes) TimeUntilNextPrayerNow() (Prayer, time.Duration, error) {
+	return p.TimeUntilNextPrayer(p.now())
+}
+
+// TimeSinceCurrentPrayer returns the prayer in effect at `t` and the time elapsed since it began.
+// Before Fajr, the current prayer is the previous day's Isha.
+func (p *PrayerTimes) TimeSinceCurrentPrayer(t time.Time) (Prayer, time.Duration, error) {
+	current := p.CurrentPrayer(t)
+	if current != NO_PRAYER {
+		return current, t.Sub(p.TimeForPrayer(current)), nil
+	}
+
+	yesterday, err := p.adjacentDay(-1)
+	if err != nil {
+		return NO_PRAYER, 0, err
+	}
+	return ISHA, t.Sub(yesterday.Isha), nil
+}
+
+// TimeSinceCurrentPrayerNow is TimeSinceCurrentPrayer evaluated at the current time of the Clock.
+func (p *PrayerTimes) TimeSinceCurrentPrayerNow() (Prayer, time.Duration, error) {
+	return p.TimeSinceCurrentPrayer(p.now())
+}
+
+// adjacentDay returns the prayer times `offset` days away from `p`, from the same source and in the
+// same time zone as `p`.
+func (p *PrayerTimes) adjacentDay(offset int) (*PrayerTimes, error) {
+	date := data.ResolveTimeByDateComponents(p.DateComponent).AddDate(0, 0, offset)
+	source := p.source
+	if source == nil {
+		source = NewCalculatedSource(p.Coords, p.CalculationParams)
+	}
+	adjacent, err := source.PrayerTimes(data.NewDateComponents(date))
+	if err != nil {
+		return nil, err
+	}
+	adjacent.setLocation(p.Fajr.Location())
+	return adjacent, nil
+}
+
 func SeasonAdjustedMorningTwilight(latitude float64, day int, year int, sunrise time.Time) time.Time {
 	a := 75.0 + ((28.65 / 55.0) * math.Abs(latitude))
 	b := 75.0 + ((19.44 / 55.0) * math.Abs(latitude))
@@ -289,12 +584,22 @@ func (p *PrayerTimes) SetTimeZone(tzone string) error {
 	if err != nil {
 		return err
 	}
+	p.setLocation(loc)
+
+	return nil
+}
+
+func (p *PrayerTimes) setLocation(loc *time.Location) {
 	p.Fajr = p.Fajr.In(loc)
 	p.Sunrise = p.Sunrise.In(loc)
 	p.Dhuhr = p.Dhuhr.In(loc)
 	p.Asr = p.Asr.In(loc)
 	p.Maghrib = p.Maghrib.In(loc)
 	p.Isha = p.Isha.In(loc)
-
-	return nil
+	if !p.AsrEnd.IsZero() {
+		p.AsrEnd = p.AsrEnd.In(loc)
+	}
+	if p.Details != nil {
+		p.Details.setLocation(loc)
+	}
 }
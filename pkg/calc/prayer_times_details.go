@@ -0,0 +1,116 @@
+package calc
+
+import "time"
+
+// PrayerTimesDetails holds the values NewPrayerTimes derived the final prayer times from.
+type PrayerTimesDetails struct {
+	// Times to the second, before any adjustments were applied and before rounding to the minute.
+	Fajr    time.Time
+	Sunrise time.Time
+	Dhuhr   time.Time
+	Asr     time.Time
+	Maghrib time.Time
+	Isha    time.Time
+
+	// The end of the time of Asr, to the second. Never adjusted.
+	AsrEnd time.Time
+
+	// The adjustments that were applied to each time, which is the sum of Adjustments and
+	// MethodAdjustments of the CalculationParameters.
+	Adjustments PrayerAdjustments
+
+	// How each time was derived.
+	FajrSource    TimeSource
+	SunriseSource TimeSource
+	DhuhrSource   TimeSource
+	AsrSource     TimeSource
+	MaghribSource TimeSource
+	IshaSource    TimeSource
+}
+
+// TimeForPrayer returns the unadjusted, unrounded time of `prayer`.
+func (d *PrayerTimesDetails) TimeForPrayer(prayer Prayer) time.Time {
+	switch prayer {
+	case FAJR:
+		return d.Fajr
+	case SUNRISE:
+		return d.Sunrise
+	case DHUHR, JUMUAH:
+		return d.Dhuhr
+	case ASR:
+		return d.Asr
+	case MAGHRIB:
+		return d.Maghrib
+	case ISHA:
+		return d.Isha
+	}
+	return time.Time{}
+}
+
+// AdjustmentForPrayer returns the adjustment in minutes that was applied to `prayer`.
+func (d *PrayerTimesDetails) AdjustmentForPrayer(prayer Prayer) int {
+	switch prayer {
+	case FAJR:
+		return d.Adjustments.FajrAdj
+	case SUNRISE:
+		return d.Adjustments.SunriseAdj
+	case DHUHR, JUMUAH:
+		return d.Adjustments.DhuhrAdj
+	case ASR:
+		return d.Adjustments.AsrAdj
+	case MAGHRIB:
+		return d.Adjustments.MaghribAdj
+	case ISHA:
+		return d.Adjustments.IshaAdj
+	}
+	return 0
+}
+
+// SourceForPrayer returns how the time of `prayer` was derived.
+func (d *PrayerTimesDetails) SourceForPrayer(prayer Prayer) TimeSource {
+	switch prayer {
+	case FAJR:
+		return d.FajrSource
+	case SUNRISE:
+		return d.SunriseSource
+	case DHUHR, JUMUAH:
+		return d.DhuhrSource
+	case ASR:
+		return d.AsrSource
+	case MAGHRIB:
+		return d.MaghribSource
+	case ISHA:
+		return d.IshaSource
+	}
+	return SOLAR_POSITION
+}
+
+func (d *PrayerTimesDetails) setAllSources(source TimeSource) {
+	d.FajrSource = source
+	d.SunriseSource = source
+	d.DhuhrSource = source
+	d.AsrSource = source
+	d.MaghribSource = source
+	d.IshaSource = source
+}
+
+func (d *PrayerTimesDetails) setSources(fajr TimeSource, isha TimeSource) {
+	d.FajrSource = fajr
+	d.SunriseSource = SOLAR_POSITION
+	d.DhuhrSource = SOLAR_POSITION
+	d.AsrSource = SOLAR_POSITION
+	d.MaghribSource = SOLAR_POSITION
+	d.IshaSource = isha
+}
+
+func (d *PrayerTimesDetails) setLocation(loc *time.Location) {
+	d.Fajr = d.Fajr.In(loc)
+	d.Sunrise = d.Sunrise.In(loc)
+	d.Dhuhr = d.Dhuhr.In(loc)
+	d.Asr = d.Asr.In(loc)
+	d.Maghrib = d.Maghrib.In(loc)
+	d.Isha = d.Isha.In(loc)
+	if !d.AsrEnd.IsZero() {
+		d.AsrEnd = d.AsrEnd.In(loc)
+	}
+}
@@ -1,6 +1,9 @@
 package calc
 
 import (
+	"errors"
+	"math"
+	"sync"
 	"testing"
 	"time"
 
@@ -234,3 +237,403 @@ func TestDaysSinceSolstice(t *testing.T) {
 		assert.Equal(t, tc.expected, dss)
 	}
 }
+
+func TestTimeUntilNextPrayer(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2015, time.Month(9), 1, 0, 0, 0, 0, time.UTC))
+	params := GetMethodParameters(KARACHI)
+	params.Madhab = HANAFI
+	coords, err := util.NewCoordinates(33.720817, 73.090032)
+	assert.Nil(t, err)
+
+	prayerTimes, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+
+	err = prayerTimes.SetTimeZone("Asia/Karachi")
+	assert.Nil(t, err)
+
+	prayer, remaining, err := prayerTimes.TimeUntilNextPrayer(prayerTimes.Asr.Add(-72 * time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, ASR, prayer)
+	assert.Equal(t, 72*time.Minute, remaining)
+
+	tomorrow, err := NewPrayerTimes(coords, data.NewDateComponents(time.Date(2015, time.Month(9), 2, 0, 0, 0, 0, time.UTC)), params)
+	assert.Nil(t, err)
+
+	afterIsha := prayerTimes.Isha.Add(time.Hour)
+	prayer, remaining, err = prayerTimes.TimeUntilNextPrayer(afterIsha)
+	assert.Nil(t, err)
+	assert.Equal(t, FAJR, prayer)
+	assert.Equal(t, tomorrow.Fajr.Sub(afterIsha), remaining)
+}
+
+func TestTimeSinceCurrentPrayer(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2015, time.Month(9), 1, 0, 0, 0, 0, time.UTC))
+	params := GetMethodParameters(KARACHI)
+	params.Madhab = HANAFI
+	coords, err := util.NewCoordinates(33.720817, 73.090032)
+	assert.Nil(t, err)
+
+	prayerTimes, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+
+	err = prayerTimes.SetTimeZone("Asia/Karachi")
+	assert.Nil(t, err)
+
+	prayer, elapsed, err := prayerTimes.TimeSinceCurrentPrayer(prayerTimes.Dhuhr.Add(15 * time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, DHUHR, prayer)
+	assert.Equal(t, 15*time.Minute, elapsed)
+
+	yesterday, err := NewPrayerTimes(coords, data.NewDateComponents(time.Date(2015, time.Month(8), 31, 0, 0, 0, 0, time.UTC)), params)
+	assert.Nil(t, err)
+
+	beforeFajr := prayerTimes.Fajr.Add(-time.Hour)
+	prayer, elapsed, err = prayerTimes.TimeSinceCurrentPrayer(beforeFajr)
+	assert.Nil(t, err)
+	assert.Equal(t, ISHA, prayer)
+	assert.Equal(t, beforeFajr.Sub(yesterday.Isha), elapsed)
+}
+
+func TestPrayerNowWithClock(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2015, time.Month(9), 1, 0, 0, 0, 0, time.UTC))
+	params := GetMethodParameters(KARACHI)
+	coords, err := util.NewCoordinates(33.720817, 73.090032)
+	assert.Nil(t, err)
+
+	prayerTimes, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+
+	clock := NewFakeClock(addSeconds(prayerTimes.Fajr, -1))
+	prayerTimes.SetClock(clock)
+	assert.Equal(t, NO_PRAYER, prayerTimes.CurrentPrayerNow())
+	assert.Equal(t, FAJR, prayerTimes.NextPrayerNow())
+
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, FAJR, prayerTimes.CurrentPrayerNow())
+	assert.Equal(t, SUNRISE, prayerTimes.NextPrayerNow())
+
+	clock.Set(prayerTimes.Dhuhr.Add(-10 * time.Minute))
+	prayer, remaining, err := prayerTimes.TimeUntilNextPrayerNow()
+	assert.Nil(t, err)
+	assert.Equal(t, DHUHR, prayer)
+	assert.Equal(t, 10*time.Minute, remaining)
+
+	prayer, elapsed, err := prayerTimes.TimeSinceCurrentPrayerNow()
+	assert.Nil(t, err)
+	assert.Equal(t, SUNRISE, prayer)
+	assert.Equal(t, prayerTimes.Dhuhr.Add(-10*time.Minute).Sub(prayerTimes.Sunrise), elapsed)
+}
+
+func TestJumuah(t *testing.T) {
+	params := GetMethodParameters(KARACHI)
+	coords, err := util.NewCoordinates(33.720817, 73.090032)
+	assert.Nil(t, err)
+
+	thursday, err := NewPrayerTimes(coords, data.NewDateComponents(time.Date(2015, time.Month(9), 3, 0, 0, 0, 0, time.UTC)), params)
+	assert.Nil(t, err)
+	assert.False(t, thursday.IsFriday())
+	assert.Equal(t, DHUHR, thursday.CurrentPrayer(addSeconds(thursday.Dhuhr, 1)))
+	assert.Equal(t, DHUHR, thursday.NextPrayer(addSeconds(thursday.Sunrise, 1)))
+	assert.Equal(t, time.Time{}, thursday.TimeForPrayer(JUMUAH))
+	assert.Nil(t, thursday.JumuahTimes([]JumuahSession{{}}))
+
+	friday, err := NewPrayerTimes(coords, data.NewDateComponents(time.Date(2015, time.Month(9), 4, 0, 0, 0, 0, time.UTC)), params)
+	assert.Nil(t, err)
+	err = friday.SetTimeZone("Asia/Karachi")
+	assert.Nil(t, err)
+
+	assert.True(t, friday.IsFriday())
+	assert.Equal(t, JUMUAH, friday.CurrentPrayer(addSeconds(friday.Dhuhr, 1)))
+	assert.Equal(t, JUMUAH, friday.NextPrayer(addSeconds(friday.Sunrise, 1)))
+	assert.Equal(t, friday.Dhuhr, friday.TimeForPrayer(JUMUAH))
+	assert.Equal(t, friday.Dhuhr, friday.TimeForPrayer(DHUHR))
+
+	loc := friday.Dhuhr.Location()
+	sessions := []JumuahSession{
+		{Khutbah: data.TimeComponents{Hours: 12, Minutes: 45}, Iqamah: data.TimeComponents{Hours: 13, Minutes: 10}},
+		{Khutbah: data.TimeComponents{Hours: 14}, Iqamah: data.TimeComponents{Hours: 14, Minutes: 20}},
+	}
+	assert.Equal(t, []JumuahTimes{
+		{Khutbah: time.Date(2015, time.Month(9), 4, 12, 45, 0, 0, loc), Iqamah: time.Date(2015, time.Month(9), 4, 13, 10, 0, 0, loc)},
+		{Khutbah: time.Date(2015, time.Month(9), 4, 14, 0, 0, 0, loc), Iqamah: time.Date(2015, time.Month(9), 4, 14, 20, 0, 0, loc)},
+	}, friday.JumuahTimes(sessions))
+
+	// Prayer times without a date are never on a Friday.
+	undated := &PrayerTimes{Fajr: friday.Fajr, Sunrise: friday.Sunrise, Dhuhr: friday.Dhuhr, Asr: friday.Asr, Maghrib: friday.Maghrib, Isha: friday.Isha}
+	assert.False(t, undated.IsFriday())
+	assert.Equal(t, DHUHR, undated.CurrentPrayer(addSeconds(friday.Dhuhr, 1)))
+	assert.Equal(t, DHUHR, undated.NextPrayer(addSeconds(friday.Sunrise, 1)))
+}
+
+func TestPrayerTimesDetails(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2015, time.Month(12), 1, 0, 0, 0, 0, time.UTC))
+	coords, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+
+	params := GetMethodParameters(MUSLIM_WORLD_LEAGUE)
+	params.Adjustments.FajrAdj = 10
+	params.Adjustments.IshaAdj = -5
+
+	prayerTimes, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+
+	err = prayerTimes.SetTimeZone("America/New_York")
+	assert.Nil(t, err)
+
+	details := prayerTimes.Details
+	assert.Equal(t, PrayerAdjustments{FajrAdj: 10, DhuhrAdj: 1, IshaAdj: -5}, details.Adjustments)
+	assert.Equal(t, "America/New_York", details.Fajr.Location().String())
+
+	for _, prayer := range []Prayer{FAJR, SUNRISE, DHUHR, ASR, MAGHRIB, ISHA} {
+		raw := details.TimeForPrayer(prayer)
+		assert.Equal(t, 0, raw.Nanosecond())
+		adjusted := raw.Add(time.Minute * time.Duration(details.AdjustmentForPrayer(prayer)))
+		assert.Equal(t, data.RoundToNearestMinute(adjusted), prayerTimes.TimeForPrayer(prayer))
+	}
+
+	assert.Equal(t, time.Date(2015, time.Month(12), 1, 10, 35, 21, 0, time.UTC), details.Fajr.UTC())
+	assert.Equal(t, time.Date(2015, time.Month(12), 1, 12, 5, 48, 0, time.UTC), details.Sunrise.UTC())
+	assert.Equal(t, time.Date(2015, time.Month(12), 1, 17, 3, 31, 0, time.UTC), details.Dhuhr.UTC())
+	assert.Equal(t, time.Date(2015, time.Month(12), 1, 19, 41, 56, 0, time.UTC), details.Asr.UTC())
+	assert.Equal(t, time.Date(2015, time.Month(12), 1, 22, 1, 1, 0, time.UTC), details.Maghrib.UTC())
+	assert.Equal(t, time.Date(2015, time.Month(12), 1, 23, 26, 23, 0, time.UTC), details.Isha.UTC())
+}
+
+func TestSourceForPrayer(t *testing.T) {
+	raleigh, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+	oslo, err := util.NewCoordinates(59.9094, 10.7349)
+	assert.Nil(t, err)
+
+	prayerTimes, err := NewPrayerTimes(raleigh, data.NewDateComponents(time.Date(2015, time.Month(7), 12, 0, 0, 0, 0, time.UTC)), GetMethodParameters(NORTH_AMERICA))
+	assert.Nil(t, err)
+	assert.Equal(t, ANGLE_BASED, prayerTimes.SourceForPrayer(FAJR))
+	assert.Equal(t, SOLAR_POSITION, prayerTimes.SourceForPrayer(SUNRISE))
+	assert.Equal(t, SOLAR_POSITION, prayerTimes.SourceForPrayer(ASR))
+	assert.Equal(t, ANGLE_BASED, prayerTimes.SourceForPrayer(ISHA))
+
+	params := GetMethodParameters(MUSLIM_WORLD_LEAGUE)
+	params.HighLatitudeRule = TWILIGHT_ANGLE
+	prayerTimes, err = NewPrayerTimes(oslo, data.NewDateComponents(time.Date(2016, time.Month(7), 1, 0, 0, 0, 0, time.UTC)), params)
+	assert.Nil(t, err)
+	assert.Equal(t, HIGH_LATITUDE_RULE, prayerTimes.SourceForPrayer(FAJR))
+	assert.Equal(t, HIGH_LATITUDE_RULE, prayerTimes.SourceForPrayer(ISHA))
+
+	prayerTimes, err = NewPrayerTimes(oslo, data.NewDateComponents(time.Date(2016, time.Month(1), 1, 0, 0, 0, 0, time.UTC)), GetMethodParameters(MOON_SIGHTING_COMMITTEE))
+	assert.Nil(t, err)
+	assert.Equal(t, SEASONAL, prayerTimes.SourceForPrayer(FAJR))
+
+	prayerTimes, err = NewPrayerTimes(raleigh, data.NewDateComponents(time.Date(2016, time.Month(1), 1, 0, 0, 0, 0, time.UTC)), GetMethodParameters(UMM_AL_QURA))
+	assert.Nil(t, err)
+	assert.Equal(t, INTERVAL, prayerTimes.SourceForPrayer(ISHA))
+}
+
+func TestErrors(t *testing.T) {
+	tromso, err := util.NewCoordinates(69.6492, 18.9553)
+	assert.Nil(t, err)
+	params := GetMethodParameters(MUSLIM_WORLD_LEAGUE)
+
+	_, err = NewPrayerTimes(tromso, data.NewDateComponents(time.Date(2020, time.Month(12), 21, 0, 0, 0, 0, time.UTC)), params)
+	assert.True(t, errors.Is(err, ErrNoSunrise))
+	var prayerErr *PrayerError
+	assert.True(t, errors.As(err, &prayerErr))
+	assert.Equal(t, SUNRISE, prayerErr.Prayer)
+
+	_, err = NewPrayerTimes(tromso, data.NewDateComponents(time.Date(2020, time.Month(6), 21, 0, 0, 0, 0, time.UTC)), params)
+	assert.True(t, errors.Is(err, ErrNoSunset))
+
+	coords, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+	date := data.NewDateComponents(time.Date(2015, time.Month(7), 12, 0, 0, 0, 0, time.UTC))
+	params.HighLatitudeRule = HighLatitudeRule(-1)
+	_, err = NewPrayerTimes(coords, date, params)
+	assert.True(t, errors.Is(err, ErrInvalidHighLatitudeRule))
+	assert.True(t, errors.As(err, &prayerErr))
+	assert.Equal(t, FAJR, prayerErr.Prayer)
+
+	_, err = NewPrayerTimes(coords, date, nil)
+	assert.True(t, errors.Is(err, ErrInvalidParameters))
+
+	_, err = NewPrayerTimes(&util.Coordinates{Latitude: 35.7750, Longitude: math.NaN()}, date, GetMethodParameters(MUSLIM_WORLD_LEAGUE))
+	assert.True(t, errors.Is(err, ErrNoTransit))
+	assert.False(t, errors.Is(err, ErrInvalidParameters))
+	assert.True(t, errors.As(err, &prayerErr))
+	assert.Equal(t, DHUHR, prayerErr.Prayer)
+}
+
+func TestNoAsrError(t *testing.T) {
+	// Days on which the sun rises always reach the altitude of Asr, so take the solar times of a
+	// polar night near the pole with the sunrise and sunset of another day.
+	pole := &util.Coordinates{Latitude: 89, Longitude: 18.9553}
+	date := data.NewDateComponents(time.Date(2020, time.Month(12), 21, 0, 0, 0, 0, time.UTC))
+	solarTime, _ := solarTimes(date, date, pole)
+	noon := time.Date(2020, time.Month(12), 21, 11, 0, 0, 0, time.UTC)
+	day := &solarDay{
+		solarTime: solarTime,
+		transit:   noon,
+		sunrise:   noon.Add(-time.Hour),
+		sunset:    noon.Add(time.Hour),
+		night:     22 * time.Hour,
+	}
+
+	_, err := prayerTimesFromSolarDay(pole, date, GetMethodParameters(MUSLIM_WORLD_LEAGUE), day, nil)
+	assert.True(t, errors.Is(err, ErrNoAsr))
+	assert.False(t, errors.Is(err, ErrNoSunrise))
+	var prayerErr *PrayerError
+	assert.True(t, errors.As(err, &prayerErr))
+	assert.Equal(t, ASR, prayerErr.Prayer)
+}
+
+func TestAsrShadowFactor(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2015, time.Month(7), 12, 0, 0, 0, 0, time.UTC))
+	coords, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+
+	params := GetMethodParameters(NORTH_AMERICA)
+	params.Madhab = HANAFI
+	hanafi, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+	assert.True(t, hanafi.AsrEnd.IsZero())
+
+	params.Madhab = SHAFI_HANBALI_MALIKI
+	params.AsrShadowFactor = 2.0
+	prayerTimes, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+	assert.Equal(t, hanafi.Asr, prayerTimes.Asr)
+
+	params.AsrShadowFactor = 1.5
+	params.AsrEndShadowFactor = 2.0
+	prayerTimes, err = NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+	assert.True(t, prayerTimes.Asr.After(hanafi.Asr.Add(-time.Hour)))
+	assert.True(t, prayerTimes.Asr.Before(hanafi.Asr))
+	assert.Equal(t, hanafi.Details.Asr, prayerTimes.Details.AsrEnd)
+	assert.True(t, prayerTimes.AsrEnd.Before(prayerTimes.Maghrib))
+
+	err = prayerTimes.SetTimeZone("America/New_York")
+	assert.Nil(t, err)
+	assert.Equal(t, "America/New_York", prayerTimes.AsrEnd.Location().String())
+}
+
+func TestFajrInterval(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2015, time.Month(7), 12, 0, 0, 0, 0, time.UTC))
+	coords, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+
+	params := GetMethodParameters(NORTH_AMERICA)
+	angleBased, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+
+	params.FajrInterval = 60
+	prayerTimes, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+	assert.Equal(t, prayerTimes.Sunrise.Add(-60*time.Minute), prayerTimes.Fajr)
+	assert.Equal(t, INTERVAL, prayerTimes.SourceForPrayer(FAJR))
+
+	// The angle based Fajr is about 86 minutes before sunrise.
+	params.FajrIntervalCombination = EARLIER_OF_ANGLE_AND_INTERVAL
+	prayerTimes, err = NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+	assert.Equal(t, angleBased.Fajr, prayerTimes.Fajr)
+	assert.Equal(t, ANGLE_BASED, prayerTimes.SourceForPrayer(FAJR))
+
+	params.FajrIntervalCombination = LATER_OF_ANGLE_AND_INTERVAL
+	prayerTimes, err = NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+	assert.Equal(t, prayerTimes.Sunrise.Add(-60*time.Minute), prayerTimes.Fajr)
+	assert.Equal(t, INTERVAL, prayerTimes.SourceForPrayer(FAJR))
+
+	params.IshaInterval = 60
+	params.IshaIntervalCombination = LATER_OF_ANGLE_AND_INTERVAL
+	prayerTimes, err = NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+	assert.Equal(t, angleBased.Isha, prayerTimes.Isha)
+	assert.Equal(t, ANGLE_BASED, prayerTimes.SourceForPrayer(ISHA))
+}
+
+func TestSeasonalIntervals(t *testing.T) {
+	coords, err := util.NewCoordinates(21.4225, 39.8262)
+	assert.Nil(t, err)
+	params := GetMethodParameters(UMM_AL_QURA)
+	params.SeasonalIntervals = []SeasonalInterval{UmmAlQuraRamadanIshaInterval()}
+
+	ramadan, err := NewPrayerTimes(coords, data.NewDateComponents(time.Date(2015, time.Month(6), 20, 0, 0, 0, 0, time.UTC)), params)
+	assert.Nil(t, err)
+	assert.Equal(t, 120*time.Minute, ramadan.Isha.Sub(ramadan.Maghrib))
+
+	shawwal, err := NewPrayerTimes(coords, data.NewDateComponents(time.Date(2015, time.Month(7), 20, 0, 0, 0, 0, time.UTC)), params)
+	assert.Nil(t, err)
+	assert.Equal(t, 90*time.Minute, shawwal.Isha.Sub(shawwal.Maghrib))
+}
+
+func TestCalculatePrayerTimes(t *testing.T) {
+	coords := util.Coordinates{Latitude: 35.7750, Longitude: -78.6336}
+	date := data.DateComponents{Year: 2015, Month: 7, Day: 12}
+
+	for method := MUSLIM_WORLD_LEAGUE; method <= UOIF; method++ {
+		expected, err := NewPrayerTimes(&coords, &date, GetMethodParameters(method))
+		assert.Nil(t, err)
+		actual, err := CalculatePrayerTimes(coords, date, MethodParameters(method))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual, method)
+	}
+
+	// The prayer times keep their own copy of the parameters.
+	params := MethodParameters(MUSLIM_WORLD_LEAGUE)
+	params.SeasonalOverrides = []SeasonalOverride{{
+		From:        MonthDay{Month: 7, Day: 1},
+		To:          MonthDay{Month: 7, Day: 31},
+		IshaAngle:   15,
+		Adjustments: &PrayerAdjustments{IshaAdj: 5},
+	}}
+	prayerTimes, err := CalculatePrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+	params.SeasonalOverrides[0].IshaAngle = 18
+	params.SeasonalOverrides[0].Adjustments.IshaAdj = 0
+	assert.InDelta(t, 15, prayerTimes.CalculationParams.SeasonalOverrides[0].IshaAngle, 0.000001)
+	assert.Equal(t, 5, prayerTimes.CalculationParams.SeasonalOverrides[0].Adjustments.IshaAdj)
+}
+
+// TestConcurrentPrayerTimes shares parameters between goroutines, for `go test -race` to check
+// that calculating prayer times does not modify them.
+func TestConcurrentPrayerTimes(t *testing.T) {
+	coords := util.Coordinates{Latitude: 35.7750, Longitude: -78.6336}
+	shared := GetMethodParameters(NORTH_AMERICA)
+	shared.Madhab = HANAFI
+	shared.SeasonalOverrides = []SeasonalOverride{{
+		From:        MonthDay{Month: 7, Day: 10},
+		To:          MonthDay{Month: 7, Day: 20},
+		Adjustments: &PrayerAdjustments{FajrAdj: 2},
+	}}
+
+	expected := make([]*PrayerTimes, 31)
+	for day := 1; day <= 31; day++ {
+		date := data.DateComponents{Year: 2015, Month: 7, Day: day}
+		prayerTimes, err := NewPrayerTimes(&coords, &date, shared)
+		assert.Nil(t, err)
+		expected[day-1] = prayerTimes
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for day := 1; day <= 31; day++ {
+				date := data.DateComponents{Year: 2015, Month: 7, Day: day}
+				var prayerTimes *PrayerTimes
+				var err error
+				if i%2 == 0 {
+					prayerTimes, err = NewPrayerTimes(&coords, &date, shared)
+				} else {
+					prayerTimes, err = CalculatePrayerTimes(coords, date, *shared)
+				}
+				assert.Nil(t, err)
+				assert.Equal(t, expected[day-1].Fajr, prayerTimes.Fajr)
+				assert.Equal(t, expected[day-1].Asr, prayerTimes.Asr)
+				assert.Equal(t, expected[day-1].Isha, prayerTimes.Isha)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,112 @@
+package calc
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	geojson "github.com/mnadev/adhango/internal/geojson"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+//go:embed regions.json
+var regionsJSON []byte
+
+// Latitude above which SEVENTH_OF_THE_NIGHT is recommended over MIDDLE_OF_THE_NIGHT.
+const seventhOfTheNightLatitude = 48.0
+
+// Recommendation is the calculation method, madhab and high latitude rule conventionally used in
+// a region.
+type Recommendation struct {
+	// ISO 3166-1 alpha-2 country code, or empty if the region is not in the dataset
+	Country string
+
+	Method           CalculationMethod
+	Madhab           AsrJuristicMethod
+	HighLatitudeRule HighLatitudeRule
+}
+
+type region struct {
+	recommendation Recommendation
+	rings          geojson.Rings
+}
+
+var regions = mustLoadRegions(regionsJSON)
+
+// RecommendationForCountry returns the conventions of `country`, an ISO 3166-1 alpha-2 code. The
+// Muslim World League method is recommended for countries without a convention in the dataset.
+func RecommendationForCountry(country string) Recommendation {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	for _, r := range regions {
+		if r.recommendation.Country == country {
+			return r.recommendation
+		}
+	}
+	return Recommendation{Country: country, Method: MUSLIM_WORLD_LEAGUE, Madhab: SHAFI_HANBALI_MALIKI, HighLatitudeRule: MIDDLE_OF_THE_NIGHT}
+}
+
+// RecommendationForCoordinates returns the conventions of the country containing `coords`.
+// Countries are simplified polygons that do not overlap, so a point within a few kilometres of a
+// border or a coast may not be matched. The second result is false outside of every country in the
+// dataset, where the Muslim World League method is returned as a default, with the high latitude
+// rule of RecommendedHighLatitudeRule.
+func RecommendationForCoordinates(coords *util.Coordinates) (Recommendation, bool) {
+	for i := range regions {
+		if regions[i].rings.Contains(coords.Latitude, coords.Longitude) {
+			return regions[i].recommendation, true
+		}
+	}
+	return Recommendation{Method: MUSLIM_WORLD_LEAGUE, Madhab: SHAFI_HANBALI_MALIKI, HighLatitudeRule: RecommendedHighLatitudeRule(coords)}, false
+}
+
+// Parameters returns the parameters of the recommended method, with the recommended madhab and
+// high latitude rule.
+func (r Recommendation) Parameters() *CalculationParameters {
+	params := GetMethodParameters(r.Method)
+	params.Madhab = r.Madhab
+	params.HighLatitudeRule = r.HighLatitudeRule
+	return params
+}
+
+// RecommendedHighLatitudeRule returns SEVENTH_OF_THE_NIGHT above 48 degrees of latitude, where
+// twilight can last all night in summer, and MIDDLE_OF_THE_NIGHT elsewhere.
+func RecommendedHighLatitudeRule(coords *util.Coordinates) HighLatitudeRule {
+	if coords.Latitude > seventhOfTheNightLatitude || coords.Latitude < -seventhOfTheNightLatitude {
+		return SEVENTH_OF_THE_NIGHT
+	}
+	return MIDDLE_OF_THE_NIGHT
+}
+
+func mustLoadRegions(b []byte) []region {
+	features, err := geojson.ReadMultiPolygons(b)
+	if err != nil {
+		panic(fmt.Sprintf("calc: reading regions: %v", err))
+	}
+
+	loaded := make([]region, 0, len(features))
+	for i, f := range features {
+		var properties struct {
+			Country          string `json:"country"`
+			Method           string `json:"method"`
+			Madhab           string `json:"madhab"`
+			HighLatitudeRule string `json:"high_latitude_rule"`
+		}
+		if err := json.Unmarshal(f.Properties, &properties); err != nil {
+			panic(fmt.Sprintf("calc: region %d: %v", i, err))
+		}
+		method, methodErr := ParseCalculationMethod(properties.Method)
+		madhab, madhabErr := ParseMadhab(properties.Madhab)
+		rule, ruleErr := ParseHighLatitudeRule(properties.HighLatitudeRule)
+		for _, err := range []error{methodErr, madhabErr, ruleErr} {
+			if err != nil {
+				panic(fmt.Sprintf("calc: region %d: %v", i, err))
+			}
+		}
+		loaded = append(loaded, region{
+			recommendation: Recommendation{Country: properties.Country, Method: method, Madhab: madhab, HighLatitudeRule: rule},
+			rings:          f.Rings,
+		})
+	}
+	return loaded
+}
@@ -0,0 +1,135 @@
+package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+func TestRecommendationForCountry(t *testing.T) {
+	r := RecommendationForCountry("sa")
+	assert.Equal(t, Recommendation{Country: "SA", Method: UMM_AL_QURA, Madhab: SHAFI_HANBALI_MALIKI, HighLatitudeRule: MIDDLE_OF_THE_NIGHT}, r)
+
+	r = RecommendationForCountry("PK")
+	assert.Equal(t, KARACHI, r.Method)
+	assert.Equal(t, HANAFI, r.Madhab)
+
+	assert.Equal(t, EGYPTIAN, RecommendationForCountry("EG").Method)
+	assert.Equal(t, DUBAI, RecommendationForCountry("AE").Method)
+
+	r = RecommendationForCountry("ZZ")
+	assert.Equal(t, "ZZ", r.Country)
+	assert.Equal(t, MUSLIM_WORLD_LEAGUE, r.Method)
+}
+
+func TestRecommendationForCoordinates(t *testing.T) {
+	for _, test := range []struct {
+		latitude, longitude float64
+		country             string
+	}{
+		{21.4225, 39.8262, "SA"},   // Mecca
+		{30.0444, 31.2357, "EG"},   // Cairo
+		{24.8607, 67.0011, "PK"},   // Karachi
+		{25.2048, 55.2708, "AE"},   // Dubai
+		{25.2854, 51.5310, "QA"},   // Doha
+		{1.3521, 103.8198, "SG"},   // Singapore
+		{35.7750, -78.6336, "US"},  // Raleigh
+		{61.2181, -149.9003, "US"}, // Anchorage
+		{51.5074, -0.1278, "GB"},   // London
+		{48.8566, 2.3522, "FR"},    // Paris
+	} {
+		coords, _ := util.NewCoordinates(test.latitude, test.longitude)
+		r, ok := RecommendationForCoordinates(coords)
+		assert.True(t, ok, test)
+		assert.Equal(t, test.country, r.Country, test)
+	}
+
+	coords, _ := util.NewCoordinates(61.2181, -149.9003)
+	r, _ := RecommendationForCoordinates(coords)
+	assert.Equal(t, SEVENTH_OF_THE_NIGHT, r.HighLatitudeRule)
+
+	// Southern Ocean
+	coords, _ = util.NewCoordinates(-60, 0)
+	r, ok := RecommendationForCoordinates(coords)
+	assert.False(t, ok)
+	assert.Equal(t, "", r.Country)
+	assert.Equal(t, MUSLIM_WORLD_LEAGUE, r.Method)
+	assert.Equal(t, SEVENTH_OF_THE_NIGHT, r.HighLatitudeRule)
+}
+
+func TestRecommendationNearBorders(t *testing.T) {
+	for _, test := range []struct {
+		latitude, longitude float64
+		country             string
+	}{
+		// Neighbours of Saudi Arabia and Russia without a convention in the dataset
+		{30.5085, 47.7804, ""},     // Basra, Iraq
+		{31.3183, 48.6706, ""},     // Ahvaz, Iran
+		{28.9684, 50.8385, ""},     // Bushehr, Iran
+		{45.7567, 126.6424, ""},    // Harbin, China
+		{47.9184, 106.9177, ""},    // Ulaanbaatar, Mongolia
+		{29.3759, 47.9774, "KW"},   // Kuwait City
+		{26.4207, 50.0888, "SA"},   // Dammam
+		{26.2285, 50.5860, "BH"},   // Manama
+		{48.4802, 135.0719, "RU"},  // Khabarovsk
+		{43.1198, 131.8869, "RU"},  // Vladivostok
+		{31.5497, 74.3436, "PK"},   // Lahore
+		{31.6340, 74.8723, "IN"},   // Amritsar
+		{23.8315, 91.2868, "IN"},   // Agartala
+		{24.8949, 91.8687, "BD"},   // Sylhet
+		{26.7271, 88.3953, "IN"},   // Siliguri
+		{41.2995, 69.2401, "UZ"},   // Tashkent
+		{42.3417, 69.5901, "KZ"},   // Shymkent
+		{40.2833, 69.6222, "TJ"},   // Khujand
+		{40.5283, 72.7985, "KG"},   // Osh
+		{54.9966, -7.3086, "GB"},   // Derry
+		{54.9503, -7.7339, "IE"},   // Letterkenny
+		{50.9513, 1.8587, "FR"},    // Calais
+		{50.6292, 3.0573, "FR"},    // Lille
+		{50.7421, 3.2147, "BE"},    // Mouscron
+		{50.8514, 5.6910, "NL"},    // Maastricht
+		{50.7753, 6.0839, "DE"},    // Aachen
+		{48.5734, 7.7521, "FR"},    // Strasbourg
+		{54.7937, 9.4469, "DE"},    // Flensburg
+		{65.8252, 24.1367, "SE"},   // Haparanda
+		{65.8485, 24.1847, "FI"},   // Tornio
+		{31.7762, -106.4425, "US"}, // El Paso
+		{31.6904, -106.4245, ""},   // Ciudad Juárez, Mexico
+		{43.6532, -79.3832, "CA"},  // Toronto
+		{42.8864, -78.8784, "US"},  // Buffalo
+	} {
+		coords, _ := util.NewCoordinates(test.latitude, test.longitude)
+		r, ok := RecommendationForCoordinates(coords)
+		assert.Equal(t, test.country != "", ok, test)
+		assert.Equal(t, test.country, r.Country, test)
+	}
+}
+
+func TestRegionsDoNotOverlap(t *testing.T) {
+	// Every quarter of a degree over the Middle East, South and Central Asia and Europe.
+	for latitude := 10.1; latitude < 71; latitude += 0.25 {
+		for longitude := -10.1; longitude < 100; longitude += 0.25 {
+			coords := &util.Coordinates{Latitude: latitude, Longitude: longitude}
+			matched := 0
+			for i := range regions {
+				if regions[i].rings.Contains(coords.Latitude, coords.Longitude) {
+					matched++
+				}
+			}
+			if matched > 1 {
+				t.Fatalf("%d regions contain %v", matched, coords)
+			}
+		}
+	}
+}
+
+func TestRecommendationParameters(t *testing.T) {
+	params := RecommendationForCountry("PK").Parameters()
+	assert.Equal(t, KARACHI, params.Method)
+	assert.Equal(t, 18.0, params.FajrAngle)
+	assert.Equal(t, HANAFI, params.Madhab)
+	assert.Equal(t, MIDDLE_OF_THE_NIGHT, params.HighLatitudeRule)
+	assert.Nil(t, params.Validate())
+}
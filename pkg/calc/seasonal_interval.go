@@ -0,0 +1,100 @@
+package calc
+
+import (
+	data "github.com/mnadev/adhango/pkg/data"
+)
+
+type IntervalCombination int64
+
+const (
+	// The interval based time is used in place of the angle based time.
+	USE_INTERVAL IntervalCombination = iota
+
+	// Whichever of the angle based and interval based times is earlier is used.
+	EARLIER_OF_ANGLE_AND_INTERVAL
+
+	// Whichever of the angle based and interval based times is later is used.
+	LATER_OF_ANGLE_AND_INTERVAL
+)
+
+type Calendar int64
+
+const (
+	GREGORIAN Calendar = iota
+
+	// The tabular Islamic calendar. See data.NewHijriDate.
+	HIJRI
+)
+
+type MonthDay struct {
+	Month int
+	Day   int
+}
+
+// SeasonalInterval replaces the FajrInterval or IshaInterval of the CalculationParameters on the
+// days from From to To inclusive. If From is after To, the range wraps around the end of the year.
+type SeasonalInterval struct {
+	// FAJR or ISHA
+	Prayer Prayer
+
+	Calendar Calendar
+	From     MonthDay
+	To       MonthDay
+
+	// Minutes before sunrise for Fajr, or after Maghrib for Isha
+	Minutes int
+}
+
+// UmmAlQuraRamadanIshaInterval returns the Umm al-Qura rule of Isha 120 minutes after Maghrib
+// during Ramadan, using the tabular Islamic calendar.
+func UmmAlQuraRamadanIshaInterval() SeasonalInterval {
+	return SeasonalInterval{
+		Prayer:   ISHA,
+		Calendar: HIJRI,
+		From:     MonthDay{Month: 9, Day: 1},
+		To:       MonthDay{Month: 9, Day: 30},
+		Minutes:  120,
+	}
+}
+
+// AppliesTo returns true if the Gregorian date `date` is within the range of the interval.
+func (s SeasonalInterval) AppliesTo(date *data.DateComponents) bool {
+	return inDateRange(s.Calendar, s.From, s.To, date)
+}
+
+// inDateRange returns true if the Gregorian date `date` is from `from` to `to` inclusive in
+// `calendar`, wrapping around the end of the year if `from` is after `to`.
+func inDateRange(calendar Calendar, from MonthDay, to MonthDay, date *data.DateComponents) bool {
+	day := MonthDay{Month: date.Month, Day: date.Day}
+	if calendar == HIJRI {
+		hijri := data.NewHijriDate(date)
+		day = MonthDay{Month: hijri.Month, Day: hijri.Day}
+	}
+	if from.before(to) || from == to {
+		return !day.before(from) && !to.before(day)
+	}
+	return !day.before(from) || !to.before(day)
+}
+
+func (m MonthDay) before(other MonthDay) bool {
+	return m.Month < other.Month || (m.Month == other.Month && m.Day < other.Day)
+}
+
+// intervals returns the Fajr and Isha intervals in minutes in effect on `date`, taking the first
+// matching SeasonalInterval for each prayer.
+func (c *CalculationParameters) intervals(date *data.DateComponents) (int, int) {
+	fajr, isha := c.FajrInterval, c.IshaInterval
+	fajrSeasonal, ishaSeasonal := false, false
+	for _, s := range c.SeasonalIntervals {
+		if (s.Prayer == FAJR && fajrSeasonal) || (s.Prayer == ISHA && ishaSeasonal) || !s.AppliesTo(date) {
+			continue
+		}
+		switch s.Prayer {
+		case FAJR:
+			fajr, fajrSeasonal = s.Minutes, true
+		case ISHA:
+			isha, ishaSeasonal = s.Minutes, true
+		}
+	}
+	return fajr, isha
+}
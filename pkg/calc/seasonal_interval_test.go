@@ -0,0 +1,33 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	data "github.com/mnadev/adhango/pkg/data"
+)
+
+func TestSeasonalIntervalAppliesTo(t *testing.T) {
+	winter := SeasonalInterval{Prayer: ISHA, Calendar: GREGORIAN, From: MonthDay{11, 1}, To: MonthDay{2, 28}, Minutes: 90}
+	testCases := []struct {
+		date time.Time
+		want bool
+	}{
+		{time.Date(2015, time.October, 31, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2015, time.November, 1, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2016, time.January, 15, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2016, time.February, 28, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC), false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, winter.AppliesTo(data.NewDateComponents(tc.date)), tc.date.String())
+	}
+
+	ramadan := UmmAlQuraRamadanIshaInterval()
+	assert.False(t, ramadan.AppliesTo(data.NewDateComponents(time.Date(2015, time.June, 17, 0, 0, 0, 0, time.UTC))))
+	assert.True(t, ramadan.AppliesTo(data.NewDateComponents(time.Date(2015, time.June, 18, 0, 0, 0, 0, time.UTC))))
+	assert.True(t, ramadan.AppliesTo(data.NewDateComponents(time.Date(2015, time.July, 17, 0, 0, 0, 0, time.UTC))))
+	assert.False(t, ramadan.AppliesTo(data.NewDateComponents(time.Date(2015, time.July, 18, 0, 0, 0, 0, time.UTC))))
+}
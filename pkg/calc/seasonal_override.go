@@ -0,0 +1,47 @@
+package calc
+
+import (
+	data "github.com/mnadev/adhango/pkg/data"
+)
+
+// SeasonalOverride replaces the angles or adjustments of the CalculationParameters on the days
+// from From to To inclusive. If From is after To, the range wraps around the end of the year.
+type SeasonalOverride struct {
+	Calendar Calendar
+	From     MonthDay
+	To       MonthDay
+
+	// Replace FajrAngle and IshaAngle if greater than 0
+	FajrAngle float64
+	IshaAngle float64
+
+	// Replaces Adjustments if not nil
+	Adjustments *PrayerAdjustments
+}
+
+// AppliesTo returns true if the Gregorian date `date` is within the range of the override.
+func (o SeasonalOverride) AppliesTo(date *data.DateComponents) bool {
+	return inDateRange(o.Calendar, o.From, o.To, date)
+}
+
+// ForDate returns the parameters in effect on `date`, with the first SeasonalOverride that applies
+// to it. The parameters themselves are returned if no override applies.
+func (c *CalculationParameters) ForDate(date *data.DateComponents) *CalculationParameters {
+	for _, o := range c.SeasonalOverrides {
+		if !o.AppliesTo(date) {
+			continue
+		}
+		params := *c
+		if o.FajrAngle > 0 {
+			params.FajrAngle = o.FajrAngle
+		}
+		if o.IshaAngle > 0 {
+			params.IshaAngle = o.IshaAngle
+		}
+		if o.Adjustments != nil {
+			params.Adjustments = *o.Adjustments
+		}
+		return &params
+	}
+	return c
+}
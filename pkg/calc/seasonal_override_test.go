@@ -0,0 +1,62 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+func TestForDate(t *testing.T) {
+	params := GetMethodParameters(MUSLIM_WORLD_LEAGUE)
+	params.SeasonalOverrides = []SeasonalOverride{
+		{Calendar: GREGORIAN, From: MonthDay{4, 1}, To: MonthDay{9, 30}, FajrAngle: 12.0, IshaAngle: 12.0},
+		{Calendar: GREGORIAN, From: MonthDay{10, 1}, To: MonthDay{3, 31}, Adjustments: &PrayerAdjustments{IshaAdj: 5}},
+	}
+
+	summer := params.ForDate(data.NewDateComponents(time.Date(2015, time.July, 12, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 12.0, summer.FajrAngle)
+	assert.Equal(t, 12.0, summer.IshaAngle)
+	assert.Equal(t, PrayerAdjustments{}, summer.Adjustments)
+
+	winter := params.ForDate(data.NewDateComponents(time.Date(2015, time.December, 12, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 18.0, winter.FajrAngle)
+	assert.Equal(t, 5, winter.Adjustments.IshaAdj)
+
+	// The parameters themselves are not modified.
+	assert.Equal(t, 18.0, params.FajrAngle)
+	assert.Equal(t, PrayerAdjustments{}, params.Adjustments)
+
+	params.SeasonalOverrides = nil
+	assert.Same(t, params, params.ForDate(data.NewDateComponents(time.Date(2015, time.July, 12, 0, 0, 0, 0, time.UTC))))
+}
+
+func TestSeasonalOverridesInPrayerTimes(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2015, time.Month(7), 12, 0, 0, 0, 0, time.UTC))
+	coords, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+
+	params := GetMethodParameters(MUSLIM_WORLD_LEAGUE)
+	params.FajrAngle = 15.0
+	params.IshaAngle = 15.0
+	params.Adjustments = PrayerAdjustments{FajrAdj: 2}
+	expected, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+
+	params = GetMethodParameters(MUSLIM_WORLD_LEAGUE)
+	params.SeasonalOverrides = []SeasonalOverride{
+		{Calendar: GREGORIAN, From: MonthDay{7, 1}, To: MonthDay{7, 31}, FajrAngle: 15.0, IshaAngle: 15.0, Adjustments: &PrayerAdjustments{FajrAdj: 2}},
+	}
+	prayerTimes, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+	assert.Equal(t, expected.Fajr, prayerTimes.Fajr)
+	assert.Equal(t, expected.Isha, prayerTimes.Isha)
+	assert.Equal(t, 2, prayerTimes.Details.Adjustments.FajrAdj)
+	assert.Same(t, params, prayerTimes.CalculationParams)
+
+	params.SeasonalOverrides[0].IshaAngle = 45.0
+	assert.NotNil(t, params.Validate())
+}
@@ -0,0 +1,109 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// SolarCalendar holds the solar times of a range of consecutive days at a location, so that the
+// prayer times of many days and many CalculationParameters can be calculated without repeating
+// the astronomical work. NewPrayerTimes computes the solar coordinates of six days for every day
+// it calculates, where a SolarCalendar of n days computes those of n+3 days once.
+//
+// A SolarCalendar is not modified after it is created, and is safe for concurrent use.
+type SolarCalendar struct {
+	coords *util.Coordinates
+	from   *data.DateComponents
+
+	// Julian day of the first day
+	julianDay float64
+
+	// One for each day, and one for the day after the last, which ends the last night
+	solarTimes []*util.SolarTime
+}
+
+// NewSolarCalendar computes the solar times at `coords` of `days` days starting at `from`.
+func NewSolarCalendar(coords *util.Coordinates, from *data.DateComponents, days int) *SolarCalendar {
+	julianDay := util.GetJulianDay(from.Year, from.Month, from.Day, 0)
+
+	// From the day before the first day to the day after the day after the last.
+	solarCoordinates := make([]*util.SolarCoordinates, days+3)
+	for i := range solarCoordinates {
+		solarCoordinates[i] = util.NewSolarCoordinates(julianDay + float64(i-1))
+	}
+	solarTimes := make([]*util.SolarTime, days+1)
+	for i := range solarTimes {
+		solarTimes[i] = util.NewSolarTimeFromSolarCoordinates(solarCoordinates[i], solarCoordinates[i+1], solarCoordinates[i+2], coords)
+	}
+
+	return &SolarCalendar{
+		coords:     coords,
+		from:       &data.DateComponents{Year: from.Year, Month: from.Month, Day: from.Day},
+		julianDay:  julianDay,
+		solarTimes: solarTimes,
+	}
+}
+
+// NewSolarCalendarSpanning computes the solar times at `coords` of every day from the earliest to
+// the latest of `dates`, e.g. the days of a published timetable. `dates` must not be empty.
+func NewSolarCalendarSpanning(coords *util.Coordinates, dates []*data.DateComponents) *SolarCalendar {
+	first := util.GetJulianDay(dates[0].Year, dates[0].Month, dates[0].Day, 0)
+	from, last := dates[0], first
+	for _, date := range dates[1:] {
+		julianDay := util.GetJulianDay(date.Year, date.Month, date.Day, 0)
+		if julianDay < first {
+			from, first = date, julianDay
+		}
+		if julianDay > last {
+			last = julianDay
+		}
+	}
+	return NewSolarCalendar(coords, from, int(math.Round(last-first))+1)
+}
+
+// Days returns the number of days in the calendar.
+func (s *SolarCalendar) Days() int {
+	return len(s.solarTimes) - 1
+}
+
+// PrayerTimes calculates the prayer times of `date` with `params`, giving the same result as
+// NewPrayerTimes. Dates outside of the calendar are calculated with NewPrayerTimes.
+func (s *SolarCalendar) PrayerTimes(date *data.DateComponents, params *CalculationParameters) (*PrayerTimes, error) {
+	if date == nil || params == nil {
+		return nil, fmt.Errorf("%w: date and parameters must not be nil", ErrInvalidParameters)
+	}
+	i, ok := s.index(date)
+	if !ok {
+		return NewPrayerTimes(s.coords, date, params)
+	}
+	return prayerTimesFromSolarTimes(s.coords, date, params, s.solarTimes[i], s.solarTimes[i+1], nil)
+}
+
+// index returns the index of `date` in the calendar, or false if it is outside of the calendar.
+func (s *SolarCalendar) index(date *data.DateComponents) (int, bool) {
+	i := int(math.Round(util.GetJulianDay(date.Year, date.Month, date.Day, 0) - s.julianDay))
+	return i, i >= 0 && i < s.Days()
+}
+
+// Timetable calculates the prayer times of every day in the calendar with `params`, in order. It
+// stops at the first day that cannot be calculated.
+func (s *SolarCalendar) Timetable(params *CalculationParameters) ([]*PrayerTimes, error) {
+	if params == nil {
+		return nil, fmt.Errorf("%w: parameters must not be nil", ErrInvalidParameters)
+	}
+
+	start := data.ResolveTimeByDateComponents(s.from)
+	days := make([]*PrayerTimes, 0, s.Days())
+	for i := 0; i < s.Days(); i++ {
+		date := data.NewDateComponents(start.AddDate(0, 0, i))
+		prayerTimes, err := prayerTimesFromSolarTimes(s.coords, date, params, s.solarTimes[i], s.solarTimes[i+1], nil)
+		if err != nil {
+			return nil, fmt.Errorf("%04d-%02d-%02d: %w", date.Year, date.Month, date.Day, err)
+		}
+		days = append(days, prayerTimes)
+	}
+	return days, nil
+}
@@ -0,0 +1,130 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+func TestSolarCalendarMatchesNewPrayerTimes(t *testing.T) {
+	raleigh, _ := util.NewCoordinates(35.7750, -78.6336)
+	oslo, _ := util.NewCoordinates(59.9139, 10.7522)
+	from := data.NewDateComponents(time.Date(2015, time.December, 20, 0, 0, 0, 0, time.UTC))
+
+	north := GetMethodParameters(MUSLIM_WORLD_LEAGUE)
+	north.HighLatitudeRule = SEVENTH_OF_THE_NIGHT
+
+	for _, test := range []struct {
+		coords *util.Coordinates
+		params *CalculationParameters
+	}{
+		{raleigh, GetMethodParameters(NORTH_AMERICA)},
+		{raleigh, GetMethodParameters(MOON_SIGHTING_COMMITTEE)},
+		{raleigh, GetMethodParameters(UMM_AL_QURA)},
+		{oslo, north},
+	} {
+		calendar := NewSolarCalendar(test.coords, from, 366)
+		days, err := calendar.Timetable(test.params)
+		assert.Nil(t, err)
+		assert.Len(t, days, 366)
+
+		for _, day := range days {
+			expected, err := NewPrayerTimes(test.coords, day.DateComponent, test.params)
+			assert.Nil(t, err)
+			assert.Equal(t, expected.Details, day.Details, day.DateComponent)
+			assert.Equal(t, expected.Fajr, day.Fajr)
+			assert.Equal(t, expected.Isha, day.Isha)
+		}
+	}
+}
+
+func TestSolarCalendarPrayerTimes(t *testing.T) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	from := data.NewDateComponents(time.Date(2015, time.July, 1, 0, 0, 0, 0, time.UTC))
+	calendar := NewSolarCalendar(coords, from, 31)
+	assert.Equal(t, 31, calendar.Days())
+
+	for _, day := range []int{1, 12, 31} {
+		date := data.NewDateComponents(time.Date(2015, time.July, day, 0, 0, 0, 0, time.UTC))
+		for _, method := range []CalculationMethod{MUSLIM_WORLD_LEAGUE, EGYPTIAN, KARACHI} {
+			params := GetMethodParameters(method)
+			expected, _ := NewPrayerTimes(coords, date, params)
+			actual, err := calendar.PrayerTimes(date, params)
+			assert.Nil(t, err)
+			assert.Equal(t, expected.Details, actual.Details)
+		}
+	}
+
+	// Outside of the calendar
+	date := data.NewDateComponents(time.Date(2015, time.August, 1, 0, 0, 0, 0, time.UTC))
+	expected, _ := NewPrayerTimes(coords, date, GetMethodParameters(KARACHI))
+	actual, err := calendar.PrayerTimes(date, GetMethodParameters(KARACHI))
+	assert.Nil(t, err)
+	assert.Equal(t, expected.Fajr, actual.Fajr)
+
+	_, err = calendar.PrayerTimes(date, nil)
+	assert.ErrorIs(t, err, ErrInvalidParameters)
+}
+
+func BenchmarkNewPrayerTimesMonth(b *testing.B) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	params := GetMethodParameters(NORTH_AMERICA)
+	start := time.Date(2015, time.July, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < b.N; i++ {
+		for day := 0; day < 30; day++ {
+			if _, err := NewPrayerTimes(coords, data.NewDateComponents(start.AddDate(0, 0, day)), params); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSolarCalendarMonth(b *testing.B) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	params := GetMethodParameters(NORTH_AMERICA)
+	from := data.NewDateComponents(time.Date(2015, time.July, 1, 0, 0, 0, 0, time.UTC))
+	for i := 0; i < b.N; i++ {
+		if _, err := NewSolarCalendar(coords, from, 30).Timetable(params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSolarCalendarMonthAllMethods(b *testing.B) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	from := data.NewDateComponents(time.Date(2015, time.July, 1, 0, 0, 0, 0, time.UTC))
+	var params []*CalculationParameters
+	for method := MUSLIM_WORLD_LEAGUE; method <= UOIF; method++ {
+		params = append(params, GetMethodParameters(method))
+	}
+	for i := 0; i < b.N; i++ {
+		calendar := NewSolarCalendar(coords, from, 30)
+		for _, p := range params {
+			if _, err := calendar.Timetable(p); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkNewPrayerTimesMonthAllMethods(b *testing.B) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	start := time.Date(2015, time.July, 1, 0, 0, 0, 0, time.UTC)
+	var params []*CalculationParameters
+	for method := MUSLIM_WORLD_LEAGUE; method <= UOIF; method++ {
+		params = append(params, GetMethodParameters(method))
+	}
+	for i := 0; i < b.N; i++ {
+		for _, p := range params {
+			for day := 0; day < 30; day++ {
+				if _, err := NewPrayerTimes(coords, data.NewDateComponents(start.AddDate(0, 0, day)), p); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+}
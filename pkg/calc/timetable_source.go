@@ -0,0 +1,76 @@
+package calc
+
+import (
+	"fmt"
+
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// TimetableSource provides the prayer times of each day, so that calculated and published
+// timetables can be used interchangeably.
+type TimetableSource interface {
+	// PrayerTimes returns the prayer times of `date`. The PrayerTimes returned belong to the
+	// caller, and methods that look at adjacent days, such as TimeUntilNextPrayer, use the same
+	// source.
+	PrayerTimes(date *data.DateComponents) (*PrayerTimes, error)
+}
+
+// CalculatedSource is a TimetableSource that calculates prayer times with NewPrayerTimes.
+type CalculatedSource struct {
+	coords *util.Coordinates
+	params *CalculationParameters
+}
+
+func NewCalculatedSource(coords *util.Coordinates, params *CalculationParameters) *CalculatedSource {
+	return &CalculatedSource{coords: coords, params: params}
+}
+
+func (s *CalculatedSource) PrayerTimes(date *data.DateComponents) (*PrayerTimes, error) {
+	prayerTimes, err := NewPrayerTimes(s.coords, date, s.params)
+	if err != nil {
+		return nil, err
+	}
+	prayerTimes.source = s
+	return prayerTimes, nil
+}
+
+// StaticSource is a TimetableSource backed by fixed prayer times, such as a mosque's published
+// timetable read with the timetable package.
+type StaticSource struct {
+	days map[data.DateComponents]*PrayerTimes
+}
+
+// NewStaticSource creates a StaticSource from the prayer times of each day in `days`, identified
+// by their DateComponent. Later days replace earlier ones with the same date.
+func NewStaticSource(days []*PrayerTimes) *StaticSource {
+	s := &StaticSource{days: map[data.DateComponents]*PrayerTimes{}}
+	for _, day := range days {
+		s.days[*day.DateComponent] = day
+	}
+	return s
+}
+
+// PrayerTimes returns a copy of the prayer times of `date`, with each source TIMETABLE. An error
+// wrapping ErrNotInTimetable is returned if there are none.
+func (s *StaticSource) PrayerTimes(date *data.DateComponents) (*PrayerTimes, error) {
+	day, ok := s.days[*date]
+	if !ok {
+		return nil, fmt.Errorf("%04d-%02d-%02d: %w", date.Year, date.Month, date.Day, ErrNotInTimetable)
+	}
+
+	prayerTimes := *day
+	prayerTimes.DateComponent = &data.DateComponents{Year: date.Year, Month: date.Month, Day: date.Day}
+	prayerTimes.Details = &PrayerTimesDetails{
+		Fajr:    day.Fajr,
+		Sunrise: day.Sunrise,
+		Dhuhr:   day.Dhuhr,
+		Asr:     day.Asr,
+		Maghrib: day.Maghrib,
+		Isha:    day.Isha,
+		AsrEnd:  day.AsrEnd,
+	}
+	prayerTimes.Details.setAllSources(TIMETABLE)
+	prayerTimes.source = s
+	return &prayerTimes, nil
+}
@@ -0,0 +1,69 @@
+package calc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+func staticDay(year int, month int, day int, loc *time.Location) *PrayerTimes {
+	at := func(hour int, minute int) time.Time {
+		return time.Date(year, time.Month(month), day, hour, minute, 0, 0, loc)
+	}
+	return &PrayerTimes{
+		Fajr:          at(4, 45),
+		Sunrise:       at(6, 10),
+		Dhuhr:         at(13, 30),
+		Asr:           at(17, 0),
+		Maghrib:       at(20, 30),
+		Isha:          at(22, 0),
+		DateComponent: &data.DateComponents{Year: year, Month: month, Day: day},
+	}
+}
+
+func TestStaticSource(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	source := NewStaticSource([]*PrayerTimes{staticDay(2015, 7, 12, loc), staticDay(2015, 7, 13, loc)})
+
+	prayerTimes, err := source.PrayerTimes(&data.DateComponents{Year: 2015, Month: 7, Day: 12})
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2015, 7, 12, 4, 45, 0, 0, loc), prayerTimes.Fajr)
+	assert.Equal(t, TIMETABLE, prayerTimes.SourceForPrayer(ISHA))
+	assert.Equal(t, ASR, prayerTimes.CurrentPrayer(time.Date(2015, 7, 12, 18, 0, 0, 0, loc)))
+
+	prayer, until, err := prayerTimes.TimeUntilNextPrayer(time.Date(2015, 7, 12, 23, 0, 0, 0, loc))
+	assert.Nil(t, err)
+	assert.Equal(t, FAJR, prayer)
+	assert.Equal(t, 5*time.Hour+45*time.Minute, until)
+
+	// The copy returned belongs to the caller.
+	err = prayerTimes.SetTimeZone("UTC")
+	assert.Nil(t, err)
+	prayerTimes, err = source.PrayerTimes(&data.DateComponents{Year: 2015, Month: 7, Day: 12})
+	assert.Nil(t, err)
+	assert.Equal(t, loc, prayerTimes.Fajr.Location())
+
+	_, _, err = prayerTimes.TimeSinceCurrentPrayer(time.Date(2015, 7, 12, 3, 0, 0, 0, loc))
+	assert.True(t, errors.Is(err, ErrNotInTimetable))
+}
+
+func TestCalculatedSource(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2015, time.Month(7), 12, 0, 0, 0, 0, time.UTC))
+	coords, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+	params := GetMethodParameters(NORTH_AMERICA)
+
+	expected, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+	var source TimetableSource = NewCalculatedSource(coords, params)
+	prayerTimes, err := source.PrayerTimes(date)
+	assert.Nil(t, err)
+	assert.Equal(t, expected.Fajr, prayerTimes.Fajr)
+	assert.Equal(t, expected.Isha, prayerTimes.Isha)
+}
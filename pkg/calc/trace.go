@@ -0,0 +1,172 @@
+package calc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+type TimeSource int64
+
+const (
+	// The time comes directly from the position of the sun: sunrise, transit, sunset or the length
+	// of the Asr shadow.
+	SOLAR_POSITION TimeSource = iota
+
+	// The time at which the sun reaches the Fajr or Isha angle below the horizon.
+	ANGLE_BASED
+
+	// The safe limit given by the fraction of the night of the HighLatitudeRule.
+	HIGH_LATITUDE_RULE
+
+	// The safe limit given by the Moonsighting Committee's seasonal adjustment.
+	SEASONAL
+
+	// The Moonsighting Committee rule of a seventh of the night, used at latitudes of 55° and above.
+	SEVENTH_OF_NIGHT_ABOVE_55
+
+	// A fixed number of minutes: before sunrise for Fajr, or after Maghrib for Isha.
+	INTERVAL
+
+	// The time was read from a published timetable by a StaticSource.
+	TIMETABLE
+)
+
+func (s TimeSource) String() string {
+	switch s {
+	case SOLAR_POSITION:
+		return "SolarPosition"
+	case ANGLE_BASED:
+		return "AngleBased"
+	case HIGH_LATITUDE_RULE:
+		return "HighLatitudeRule"
+	case SEASONAL:
+		return "Seasonal"
+	case SEVENTH_OF_NIGHT_ABOVE_55:
+		return "SeventhOfNightAbove55"
+	case INTERVAL:
+		return "Interval"
+	case TIMETABLE:
+		return "Timetable"
+	}
+	return "Unknown"
+}
+
+// Candidate is a time considered for a prayer.
+type Candidate struct {
+	Source TimeSource
+
+	// The zero time if the source yields no time, e.g. when the sun never reaches the Fajr angle.
+	Time time.Time
+}
+
+// PrayerTrace explains how the time of a single prayer was derived.
+type PrayerTrace struct {
+	Prayer Prayer
+
+	// The times considered, in the order they were evaluated.
+	Candidates []Candidate
+
+	// The source of the candidate that was chosen, and why it was chosen.
+	Chosen TimeSource
+	Reason string
+
+	// The chosen time before adjustments and rounding, the adjustment in minutes, and the final time.
+	Time       time.Time
+	Adjustment int
+	Final      time.Time
+}
+
+// CalculationTrace explains how NewPrayerTimesWithTrace derived each prayer time.
+type CalculationTrace struct {
+	// The time from sunset until sunrise the following day.
+	Night time.Duration
+
+	// The fractions of the night used for the safe limits of Fajr and Isha.
+	NightPortions NightPortions
+
+	// One entry for each of Fajr, Sunrise, Dhuhr, Asr, Maghrib and Isha, in that order.
+	Prayers []*PrayerTrace
+}
+
+// NewPrayerTimesWithTrace is NewPrayerTimes, additionally returning an explanation of how each time
+// was derived.
+func NewPrayerTimesWithTrace(coords *util.Coordinates, date *data.DateComponents, params *CalculationParameters) (*PrayerTimes, *CalculationTrace, error) {
+	trace := &CalculationTrace{}
+	for _, prayer := range []Prayer{FAJR, SUNRISE, DHUHR, ASR, MAGHRIB, ISHA} {
+		trace.Prayers = append(trace.Prayers, &PrayerTrace{Prayer: prayer})
+	}
+	prayerTimes, err := newPrayerTimes(coords, date, params, trace)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prayerTimes, trace, nil
+}
+
+// ForPrayer returns the trace of `prayer`, or nil if there is none.
+func (t *CalculationTrace) ForPrayer(prayer Prayer) *PrayerTrace {
+	if prayer == JUMUAH {
+		prayer = DHUHR
+	}
+	for _, p := range t.Prayers {
+		if p.Prayer == prayer {
+			return p
+		}
+	}
+	return nil
+}
+
+// String formats the trace as a human readable explanation, with times in UTC.
+func (t *CalculationTrace) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Night: %v, night portions: Fajr %.4f, Isha %.4f\n", t.Night, t.NightPortions.Fajr, t.NightPortions.Isha)
+	for _, p := range t.Prayers {
+		candidates := make([]string, 0, len(p.Candidates))
+		for _, c := range p.Candidates {
+			candidates = append(candidates, fmt.Sprintf("%v %s", c.Source, formatTraceTime(c.Time)))
+		}
+		fmt.Fprintf(&b, "%v: %s from %v (%s); candidates: %s; adjustment %d min; final %s\n",
+			p.Prayer, formatTraceTime(p.Time), p.Chosen, p.Reason, strings.Join(candidates, ", "), p.Adjustment, formatTraceTime(p.Final))
+	}
+	return b.String()
+}
+
+func formatTraceTime(t time.Time) string {
+	if t.IsZero() {
+		return "none"
+	}
+	return t.UTC().Format("15:04:05")
+}
+
+// The following methods do nothing on a nil trace, so that NewPrayerTimes can record into a trace
+// unconditionally.
+
+func (t *CalculationTrace) addCandidate(prayer Prayer, source TimeSource, candidate time.Time) {
+	if t == nil {
+		return
+	}
+	p := t.ForPrayer(prayer)
+	p.Candidates = append(p.Candidates, Candidate{Source: source, Time: candidate})
+}
+
+func (t *CalculationTrace) choose(prayer Prayer, source TimeSource, chosen time.Time, reason string) {
+	if t == nil {
+		return
+	}
+	p := t.ForPrayer(prayer)
+	p.Chosen = source
+	p.Time = chosen
+	p.Reason = reason
+}
+
+func (t *CalculationTrace) finish(prayer Prayer, adjustment int, final time.Time) {
+	if t == nil {
+		return
+	}
+	p := t.ForPrayer(prayer)
+	p.Adjustment = adjustment
+	p.Final = final
+}
@@ -0,0 +1,102 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+func TestTraceMatchesPrayerTimes(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2015, time.Month(7), 12, 0, 0, 0, 0, time.UTC))
+	params := GetMethodParameters(NORTH_AMERICA)
+	coords, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+
+	expected, err := NewPrayerTimes(coords, date, params)
+	assert.Nil(t, err)
+
+	prayerTimes, trace, err := NewPrayerTimesWithTrace(coords, date, params)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, prayerTimes)
+
+	assert.Len(t, trace.Prayers, 6)
+	assert.InDelta(t, 9.5, trace.Night.Hours(), 0.5)
+	assert.InDelta(t, 0.5, trace.NightPortions.Fajr, 0.0001)
+
+	for _, p := range trace.Prayers {
+		assert.Equal(t, prayerTimes.TimeForPrayer(p.Prayer), p.Final)
+		assert.Equal(t, prayerTimes.Details.TimeForPrayer(p.Prayer), p.Time)
+		assert.Equal(t, prayerTimes.Details.AdjustmentForPrayer(p.Prayer), p.Adjustment)
+	}
+
+	fajr := trace.ForPrayer(FAJR)
+	assert.Equal(t, ANGLE_BASED, fajr.Chosen)
+	assert.Equal(t, []TimeSource{ANGLE_BASED, HIGH_LATITUDE_RULE}, candidateSources(fajr))
+	assert.Equal(t, SOLAR_POSITION, trace.ForPrayer(ASR).Chosen)
+	assert.Equal(t, ANGLE_BASED, trace.ForPrayer(ISHA).Chosen)
+	assert.Contains(t, trace.String(), "Fajr: 08:42:28 from AngleBased")
+}
+
+func TestTraceHighLatitudeFallback(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2016, time.Month(7), 1, 0, 0, 0, 0, time.UTC))
+	params := GetMethodParameters(MUSLIM_WORLD_LEAGUE)
+	params.HighLatitudeRule = TWILIGHT_ANGLE
+	coords, err := util.NewCoordinates(59.9094, 10.7349)
+	assert.Nil(t, err)
+
+	_, trace, err := NewPrayerTimesWithTrace(coords, date, params)
+	assert.Nil(t, err)
+
+	fajr := trace.ForPrayer(FAJR)
+	assert.Equal(t, HIGH_LATITUDE_RULE, fajr.Chosen)
+	assert.Equal(t, "the sun does not reach the Fajr angle", fajr.Reason)
+	assert.True(t, fajr.Candidates[0].Time.IsZero())
+	assert.Equal(t, fajr.Candidates[1].Time, fajr.Time)
+
+	isha := trace.ForPrayer(ISHA)
+	assert.Equal(t, HIGH_LATITUDE_RULE, isha.Chosen)
+}
+
+func TestTraceMoonsightingCommittee(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2016, time.Month(1), 1, 0, 0, 0, 0, time.UTC))
+	coords, err := util.NewCoordinates(59.9094, 10.7349)
+	assert.Nil(t, err)
+
+	_, trace, err := NewPrayerTimesWithTrace(coords, date, GetMethodParameters(MOON_SIGHTING_COMMITTEE))
+	assert.Nil(t, err)
+
+	fajr := trace.ForPrayer(FAJR)
+	assert.Equal(t, []TimeSource{ANGLE_BASED, SEVENTH_OF_NIGHT_ABOVE_55, SEASONAL}, candidateSources(fajr))
+	assert.Equal(t, SEASONAL, fajr.Chosen)
+	assert.Equal(t, "calculated time is earlier than the safe limit", fajr.Reason)
+
+	isha := trace.ForPrayer(ISHA)
+	assert.Equal(t, []TimeSource{ANGLE_BASED, SEVENTH_OF_NIGHT_ABOVE_55, SEASONAL}, candidateSources(isha))
+	assert.Equal(t, SEASONAL, isha.Chosen)
+}
+
+func TestTraceIshaInterval(t *testing.T) {
+	date := data.NewDateComponents(time.Date(2016, time.Month(1), 1, 0, 0, 0, 0, time.UTC))
+	coords, err := util.NewCoordinates(21.4225, 39.8262)
+	assert.Nil(t, err)
+
+	prayerTimes, trace, err := NewPrayerTimesWithTrace(coords, date, GetMethodParameters(UMM_AL_QURA))
+	assert.Nil(t, err)
+
+	isha := trace.ForPrayer(ISHA)
+	assert.Equal(t, []TimeSource{INTERVAL}, candidateSources(isha))
+	assert.Equal(t, INTERVAL, isha.Chosen)
+	assert.Equal(t, prayerTimes.Details.Maghrib.Add(90*time.Minute), isha.Time)
+}
+
+func candidateSources(p *PrayerTrace) []TimeSource {
+	sources := make([]TimeSource, 0, len(p.Candidates))
+	for _, c := range p.Candidates {
+		sources = append(sources, c.Source)
+	}
+	return sources
+}
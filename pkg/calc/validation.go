@@ -0,0 +1,157 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// The sun is more than 18° below the horizon once astronomical twilight ends, so larger Fajr
+	// and Isha angles leave plenty of room for any method in use.
+	maxTwilightAngle = 30.0
+
+	maxShadowFactor      = 10.0
+	maxInterval          = 180
+	maxAdjustmentMinutes = 180
+)
+
+// ValidationError lists every problem found by CalculationParameters.Validate. It matches
+// ErrInvalidParameters with errors.Is.
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		problems = append(problems, p.Error())
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalidParameters, strings.Join(problems, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidParameters
+}
+
+// Validate checks that the parameters can be used to calculate prayer times, returning a
+// *ValidationError listing all problems found, or nil if there are none.
+func (c *CalculationParameters) Validate() error {
+	var problems []error
+
+	if c.Method < OTHER || c.Method > UOIF {
+		problems = append(problems, fmt.Errorf("unknown calculation method %d", c.Method))
+	}
+	if c.FajrInterval < 0 || c.FajrInterval > maxInterval {
+		problems = append(problems, fmt.Errorf("FajrInterval must be between 0 and %d minutes, got %d", maxInterval, c.FajrInterval))
+	}
+	if !(c.FajrInterval > 0 && c.FajrIntervalCombination == USE_INTERVAL) && !isValidTwilightAngle(c.FajrAngle) {
+		problems = append(problems, fmt.Errorf("FajrAngle must be greater than 0 and at most %v unless FajrInterval is used, got %v", maxTwilightAngle, c.FajrAngle))
+	}
+	if c.IshaInterval < 0 || c.IshaInterval > maxInterval {
+		problems = append(problems, fmt.Errorf("IshaInterval must be between 0 and %d minutes, got %d", maxInterval, c.IshaInterval))
+	}
+	if !(c.IshaInterval > 0 && c.IshaIntervalCombination == USE_INTERVAL) && !isValidTwilightAngle(c.IshaAngle) {
+		problems = append(problems, fmt.Errorf("IshaAngle must be greater than 0 and at most %v unless IshaInterval is used, got %v", maxTwilightAngle, c.IshaAngle))
+	}
+	for _, combination := range []IntervalCombination{c.FajrIntervalCombination, c.IshaIntervalCombination} {
+		if combination < USE_INTERVAL || combination > LATER_OF_ANGLE_AND_INTERVAL {
+			problems = append(problems, fmt.Errorf("unknown interval combination %d", combination))
+		}
+	}
+	for i, seasonal := range c.SeasonalIntervals {
+		if err := seasonal.validate(); err != nil {
+			problems = append(problems, fmt.Errorf("seasonal interval %d: %w", i, err))
+		}
+	}
+	if math.IsNaN(c.AsrShadowFactor) || c.AsrShadowFactor < 0 || c.AsrShadowFactor > maxShadowFactor {
+		problems = append(problems, fmt.Errorf("AsrShadowFactor must be between 0 and %v, got %v", maxShadowFactor, c.AsrShadowFactor))
+	} else if _, ok := c.Madhab.ShadowLength(); !ok && c.AsrShadowFactor == 0 {
+		problems = append(problems, fmt.Errorf("unknown madhab %d", c.Madhab))
+	}
+	if math.IsNaN(c.AsrEndShadowFactor) || c.AsrEndShadowFactor < 0 || c.AsrEndShadowFactor > maxShadowFactor {
+		problems = append(problems, fmt.Errorf("AsrEndShadowFactor must be between 0 and %v, got %v", maxShadowFactor, c.AsrEndShadowFactor))
+	} else if c.AsrEndShadowFactor > 0 && c.AsrEndShadowFactor <= c.ShadowFactor() {
+		problems = append(problems, fmt.Errorf("AsrEndShadowFactor must be greater than the shadow factor of Asr, %v, got %v", c.ShadowFactor(), c.AsrEndShadowFactor))
+	}
+	if _, err := c.NightPortions(); err != nil {
+		problems = append(problems, fmt.Errorf("%w %d", err, c.HighLatitudeRule))
+	}
+	for i, override := range c.SeasonalOverrides {
+		if err := override.validate(); err != nil {
+			problems = append(problems, fmt.Errorf("seasonal override %d: %w", i, err))
+		}
+	}
+	problems = append(problems, validateAdjustments("Adjustments", c.Adjustments)...)
+	problems = append(problems, validateAdjustments("MethodAdjustments", c.MethodAdjustments)...)
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func isValidTwilightAngle(angle float64) bool {
+	return !math.IsNaN(angle) && angle > 0 && angle <= maxTwilightAngle
+}
+
+func validateAdjustments(name string, a PrayerAdjustments) []error {
+	var problems []error
+	for _, adj := range []struct {
+		prayer  Prayer
+		minutes int
+	}{
+		{FAJR, a.FajrAdj},
+		{SUNRISE, a.SunriseAdj},
+		{DHUHR, a.DhuhrAdj},
+		{ASR, a.AsrAdj},
+		{MAGHRIB, a.MaghribAdj},
+		{ISHA, a.IshaAdj},
+	} {
+		if adj.minutes < -maxAdjustmentMinutes || adj.minutes > maxAdjustmentMinutes {
+			problems = append(problems, fmt.Errorf("%s for %v must be between -%d and %d minutes, got %d", name, adj.prayer, maxAdjustmentMinutes, maxAdjustmentMinutes, adj.minutes))
+		}
+	}
+	return problems
+}
+
+func (s SeasonalInterval) validate() error {
+	if s.Prayer != FAJR && s.Prayer != ISHA {
+		return fmt.Errorf("prayer must be Fajr or Isha, got %v", s.Prayer)
+	}
+	if s.Minutes <= 0 || s.Minutes > maxInterval {
+		return fmt.Errorf("minutes must be greater than 0 and at most %d, got %d", maxInterval, s.Minutes)
+	}
+	return validateDateRange(s.Calendar, s.From, s.To)
+}
+
+func (o SeasonalOverride) validate() error {
+	for _, angle := range []float64{o.FajrAngle, o.IshaAngle} {
+		if angle != 0 && !isValidTwilightAngle(angle) {
+			return fmt.Errorf("angles must be 0 or greater than 0 and at most %v, got %v", maxTwilightAngle, angle)
+		}
+	}
+	if o.Adjustments != nil {
+		if problems := validateAdjustments("Adjustments", *o.Adjustments); len(problems) > 0 {
+			return problems[0]
+		}
+	}
+	return validateDateRange(o.Calendar, o.From, o.To)
+}
+
+func validateDateRange(calendar Calendar, from MonthDay, to MonthDay) error {
+	maxDay := 31
+	switch calendar {
+	case GREGORIAN:
+	case HIJRI:
+		maxDay = 30
+	default:
+		return fmt.Errorf("unknown calendar %d", calendar)
+	}
+	for _, d := range []MonthDay{from, to} {
+		if d.Month < 1 || d.Month > 12 || d.Day < 1 || d.Day > maxDay {
+			return fmt.Errorf("invalid month and day %d-%d", d.Month, d.Day)
+		}
+	}
+	return nil
+}
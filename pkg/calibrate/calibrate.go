@@ -0,0 +1,365 @@
+package calibrate
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// Differences in the sum of squared minutes below this are treated as ties.
+const tolerance = 1e-6
+
+var calibratedPrayers = []calc.Prayer{calc.FAJR, calc.SUNRISE, calc.DHUHR, calc.ASR, calc.MAGHRIB, calc.ISHA}
+
+// Observation is the time of a prayer as published by a mosque.
+type Observation struct {
+	// The date of the timetable entry, which may differ from the local date of Time, e.g. for an
+	// Isha after midnight.
+	Date   *data.DateComponents
+	Prayer calc.Prayer
+	Time   time.Time
+}
+
+// Residuals summarises the differences between observed and calculated times. Positive values
+// mean the observed time is later.
+type Residuals struct {
+	Count int
+	Mean  time.Duration
+	RMS   time.Duration
+
+	// The largest absolute difference
+	Max time.Duration
+}
+
+type Result struct {
+	// The best fitting parameters, including the adjustments for each prayer
+	Params *calc.CalculationParameters
+
+	Prayers map[calc.Prayer]Residuals
+	Overall Residuals
+}
+
+// Calibrator searches for the CalculationParameters that best fit a set of observations. Both the
+// known calculation methods and custom Fajr and Isha angles and intervals are considered, along
+// with each madhab and high latitude rule, and the adjustment of each prayer is fitted on top.
+type Calibrator struct {
+	coords            *util.Coordinates
+	methods           []calc.CalculationMethod
+	highLatitudeRules []calc.HighLatitudeRule
+	minAngle          float64
+	maxAngle          float64
+	angleStep         float64
+}
+
+// NewCalibrator creates a Calibrator for `coords`. By default all calculation methods and high
+// latitude rules are considered, and custom angles from 10 to 20 in steps of 0.5.
+func NewCalibrator(coords *util.Coordinates) *Calibrator {
+	return &Calibrator{
+		coords: coords,
+		methods: []calc.CalculationMethod{
+			calc.MUSLIM_WORLD_LEAGUE, calc.EGYPTIAN, calc.KARACHI, calc.UMM_AL_QURA, calc.DUBAI,
+			calc.MOON_SIGHTING_COMMITTEE, calc.NORTH_AMERICA, calc.KUWAIT, calc.QATAR, calc.SINGAPORE,
+			calc.UOIF,
+		},
+		highLatitudeRules: []calc.HighLatitudeRule{calc.MIDDLE_OF_THE_NIGHT, calc.SEVENTH_OF_THE_NIGHT, calc.TWILIGHT_ANGLE},
+		minAngle:          10.0,
+		maxAngle:          20.0,
+		angleStep:         0.5,
+	}
+}
+
+// SetMethods sets the calculation methods considered.
+func (c *Calibrator) SetMethods(methods ...calc.CalculationMethod) *Calibrator {
+	c.methods = methods
+	return c
+}
+
+// SetHighLatitudeRules sets the high latitude rules considered.
+func (c *Calibrator) SetHighLatitudeRules(rules ...calc.HighLatitudeRule) *Calibrator {
+	c.highLatitudeRules = rules
+	return c
+}
+
+// SetAngleRange sets the custom Fajr and Isha angles considered, from `min` to `max` inclusive in
+// increments of `step`. A `step` of 0 disables custom angles.
+func (c *Calibrator) SetAngleRange(min float64, max float64, step float64) *Calibrator {
+	c.minAngle, c.maxAngle, c.angleStep = min, max, step
+	return c
+}
+
+// Calibrate returns the parameters that best fit `observations`, in the least squares sense, and
+// the residuals of the times calculated with them.
+func (c *Calibrator) Calibrate(observations []Observation) (*Result, error) {
+	if len(observations) == 0 {
+		return nil, fmt.Errorf("no observations")
+	}
+	for i, o := range observations {
+		if o.Date == nil || o.Time.IsZero() {
+			return nil, fmt.Errorf("observation %d: date and time must be set", i)
+		}
+		if o.Prayer < calc.FAJR || o.Prayer > calc.ISHA {
+			return nil, fmt.Errorf("observation %d: cannot calibrate %v", i, o.Prayer)
+		}
+	}
+
+	// Every candidate is calculated for the same days, so their solar times are computed once.
+	calendar := newSolarCalendar(c.coords, observations)
+
+	var best *fit
+	for _, method := range c.methods {
+		for _, rule := range c.highLatitudeRules {
+			for _, madhab := range []calc.AsrJuristicMethod{calc.SHAFI_HANBALI_MALIKI, calc.HANAFI} {
+				params := calc.GetMethodParameters(method)
+				params.HighLatitudeRule = rule
+				params.Madhab = madhab
+				if f := c.fit(calendar, params, observations); f != nil && f.betterThan(best) {
+					best = f
+				}
+			}
+		}
+	}
+	if f := c.fitCustom(calendar, observations); f != nil && f.betterThan(best) {
+		best = f
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no parameters can calculate the observed prayer times")
+	}
+
+	params := best.params
+	params.Adjustments = calc.PrayerAdjustments{
+		FajrAdj:    best.adjustments[calc.FAJR] - params.MethodAdjustments.FajrAdj,
+		SunriseAdj: best.adjustments[calc.SUNRISE] - params.MethodAdjustments.SunriseAdj,
+		DhuhrAdj:   best.adjustments[calc.DHUHR] - params.MethodAdjustments.DhuhrAdj,
+		AsrAdj:     best.adjustments[calc.ASR] - params.MethodAdjustments.AsrAdj,
+		MaghribAdj: best.adjustments[calc.MAGHRIB] - params.MethodAdjustments.MaghribAdj,
+		IshaAdj:    best.adjustments[calc.ISHA] - params.MethodAdjustments.IshaAdj,
+	}
+	return c.result(calendar, params, observations)
+}
+
+// fit holds parameters along with the adjustment, in minutes, that best fits each prayer and the
+// remaining sum of squared differences.
+type fit struct {
+	params      *calc.CalculationParameters
+	adjustments map[calc.Prayer]int
+	errors      map[calc.Prayer]float64
+}
+
+func (f *fit) sse() float64 {
+	total := 0.0
+	for _, prayer := range calibratedPrayers {
+		total += f.errors[prayer]
+	}
+	return total
+}
+
+// betterThan returns true if `f` fits better than `other`. Parameters considered earlier are kept
+// on a tie, so known methods are preferred over custom angles that fit equally well.
+func (f *fit) betterThan(other *fit) bool {
+	return other == nil || f.sse() < other.sse()-tolerance
+}
+
+// fit fits adjustments to `params`, or returns nil if the times of some observations cannot be
+// calculated with them.
+func (c *Calibrator) fit(calendar *calc.SolarCalendar, params *calc.CalculationParameters, observations []Observation) *fit {
+	differences, ok := c.differences(calendar, params, observations)
+	if !ok {
+		return nil
+	}
+	f := &fit{params: params, adjustments: map[calc.Prayer]int{}, errors: map[calc.Prayer]float64{}}
+	for prayer, d := range differences {
+		f.adjustments[prayer], f.errors[prayer] = fitAdjustment(d)
+	}
+	return f
+}
+
+// fitCustom fits custom angles or intervals for Fajr and Isha, picking the madhab and high latitude
+// rule separately since Fajr and Isha do not depend on the madhab and Asr does not depend on the
+// high latitude rule.
+func (c *Calibrator) fitCustom(calendar *calc.SolarCalendar, observations []Observation) *fit {
+	var best *fit
+	for _, rule := range c.highLatitudeRules {
+		var custom *fit
+		// Fajr and Isha each depend only on their own angle, so both are fitted in the same pass.
+		for _, angle := range c.angles() {
+			params := calc.NewCalculationParametersBuilder().
+				SetFajrAngle(angle).
+				SetIshaAngle(angle).
+				SetHighLatitudeRule(rule).
+				Build()
+			f := c.fit(calendar, params, observations)
+			if f == nil {
+				continue
+			}
+			if custom == nil {
+				custom = f
+				continue
+			}
+			if f.errors[calc.FAJR] < custom.errors[calc.FAJR]-tolerance {
+				custom.params.FajrAngle = angle
+				custom.adjustments[calc.FAJR], custom.errors[calc.FAJR] = f.adjustments[calc.FAJR], f.errors[calc.FAJR]
+			}
+			if f.errors[calc.ISHA] < custom.errors[calc.ISHA]-tolerance {
+				custom.params.IshaAngle = angle
+				custom.adjustments[calc.ISHA], custom.errors[calc.ISHA] = f.adjustments[calc.ISHA], f.errors[calc.ISHA]
+			}
+		}
+		if custom == nil {
+			continue
+		}
+
+		// An interval of 0 relative to sunrise and Maghrib, so the fitted adjustment is the interval.
+		params := calc.NewCalculationParametersBuilder().
+			SetFajrAngle(custom.params.FajrAngle).
+			SetIshaAngle(custom.params.IshaAngle).
+			SetHighLatitudeRule(rule).
+			Build()
+		if differences, ok := c.intervalDifferences(calendar, params, observations); ok {
+			if adj, e := fitAdjustment(differences[calc.FAJR]); adj < 0 && e < custom.errors[calc.FAJR]-tolerance {
+				custom.params.FajrInterval = -adj
+				custom.adjustments[calc.FAJR], custom.errors[calc.FAJR] = 0, e
+			}
+			if adj, e := fitAdjustment(differences[calc.ISHA]); adj > 0 && e < custom.errors[calc.ISHA]-tolerance {
+				custom.params.IshaInterval = adj
+				custom.adjustments[calc.ISHA], custom.errors[calc.ISHA] = 0, e
+			}
+		}
+
+		hanafi := *custom.params
+		hanafi.Madhab = calc.HANAFI
+		if f := c.fit(calendar, &hanafi, observations); f != nil && f.errors[calc.ASR] < custom.errors[calc.ASR]-tolerance {
+			custom.params.Madhab = calc.HANAFI
+			custom.adjustments[calc.ASR], custom.errors[calc.ASR] = f.adjustments[calc.ASR], f.errors[calc.ASR]
+		}
+
+		// Each choice above was made on the errors of other parameters, e.g. the Fajr angle along
+		// with an Isha angle other than the one chosen, so the chosen parameters are fitted again
+		// and compared by their own errors.
+		if f := c.fit(calendar, custom.params, observations); f != nil && f.betterThan(best) {
+			best = f
+		}
+	}
+	return best
+}
+
+func (c *Calibrator) angles() []float64 {
+	var angles []float64
+	if c.angleStep <= 0 {
+		return angles
+	}
+	for i := 0; c.minAngle+float64(i)*c.angleStep <= c.maxAngle+1e-9; i++ {
+		angles = append(angles, c.minAngle+float64(i)*c.angleStep)
+	}
+	return angles
+}
+
+// differences returns the minutes by which each observation is later than the unadjusted,
+// unrounded time calculated with `params`, grouped by prayer. False is returned if the times
+// cannot be calculated.
+func (c *Calibrator) differences(calendar *calc.SolarCalendar, params *calc.CalculationParameters, observations []Observation) (map[calc.Prayer][]float64, bool) {
+	return c.differencesFrom(calendar, params, observations, func(p *calc.PrayerTimes, prayer calc.Prayer) time.Time {
+		return p.Details.TimeForPrayer(prayer)
+	})
+}
+
+// intervalDifferences is differences, with Fajr measured from sunrise and Isha from Maghrib.
+func (c *Calibrator) intervalDifferences(calendar *calc.SolarCalendar, params *calc.CalculationParameters, observations []Observation) (map[calc.Prayer][]float64, bool) {
+	return c.differencesFrom(calendar, params, observations, func(p *calc.PrayerTimes, prayer calc.Prayer) time.Time {
+		switch prayer {
+		case calc.FAJR:
+			return p.Details.Sunrise
+		case calc.ISHA:
+			return p.Details.Maghrib
+		}
+		return p.Details.TimeForPrayer(prayer)
+	})
+}
+
+func (c *Calibrator) differencesFrom(calendar *calc.SolarCalendar, params *calc.CalculationParameters, observations []Observation, timeFor func(*calc.PrayerTimes, calc.Prayer) time.Time) (map[calc.Prayer][]float64, bool) {
+	prayerTimes := map[data.DateComponents]*calc.PrayerTimes{}
+	differences := map[calc.Prayer][]float64{}
+	for _, o := range observations {
+		p, ok := prayerTimes[*o.Date]
+		if !ok {
+			var err error
+			p, err = calendar.PrayerTimes(o.Date, params)
+			if err != nil {
+				return nil, false
+			}
+			prayerTimes[*o.Date] = p
+		}
+		differences[o.Prayer] = append(differences[o.Prayer], o.Time.Sub(timeFor(p, o.Prayer)).Minutes())
+	}
+	return differences, true
+}
+
+// newSolarCalendar returns a SolarCalendar at `coords` spanning the dates of `observations`.
+func newSolarCalendar(coords *util.Coordinates, observations []Observation) *calc.SolarCalendar {
+	dates := make([]*data.DateComponents, len(observations))
+	for i, o := range observations {
+		dates[i] = o.Date
+	}
+	return calc.NewSolarCalendarSpanning(coords, dates)
+}
+
+// fitAdjustment returns the whole number of minutes that best fits `differences` and the sum of
+// squared differences that remain.
+func fitAdjustment(differences []float64) (int, float64) {
+	if len(differences) == 0 {
+		return 0, 0
+	}
+	mean := 0.0
+	for _, d := range differences {
+		mean += d
+	}
+	mean /= float64(len(differences))
+	adj := int(math.Round(mean))
+
+	sse := 0.0
+	for _, d := range differences {
+		sse += (d - float64(adj)) * (d - float64(adj))
+	}
+	return adj, sse
+}
+
+// result calculates the residuals of `observations` against the prayer times of `params`.
+func (c *Calibrator) result(calendar *calc.SolarCalendar, params *calc.CalculationParameters, observations []Observation) (*Result, error) {
+	byPrayer := map[calc.Prayer][]time.Duration{}
+	var all []time.Duration
+	for _, o := range observations {
+		p, err := calendar.PrayerTimes(o.Date, params)
+		if err != nil {
+			return nil, err
+		}
+		d := o.Time.Sub(p.TimeForPrayer(o.Prayer))
+		byPrayer[o.Prayer] = append(byPrayer[o.Prayer], d)
+		all = append(all, d)
+	}
+
+	result := &Result{Params: params, Prayers: map[calc.Prayer]Residuals{}, Overall: newResiduals(all)}
+	for _, prayer := range calibratedPrayers {
+		if d, ok := byPrayer[prayer]; ok {
+			result.Prayers[prayer] = newResiduals(d)
+		}
+	}
+	return result, nil
+}
+
+func newResiduals(differences []time.Duration) Residuals {
+	r := Residuals{Count: len(differences)}
+	sum, squares := 0.0, 0.0
+	for _, d := range differences {
+		sum += float64(d)
+		squares += float64(d) * float64(d)
+		if d.Abs() > r.Max {
+			r.Max = d.Abs()
+		}
+	}
+	r.Mean = time.Duration(sum / float64(len(differences)))
+	r.RMS = time.Duration(math.Sqrt(squares / float64(len(differences))))
+	return r
+}
@@ -0,0 +1,106 @@
+package calibrate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// timetable returns an observation of each prayer, calculated with `params`, for every week of 2015.
+func timetable(t *testing.T, coords *util.Coordinates, params *calc.CalculationParameters) []Observation {
+	var observations []Observation
+	for day := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC); day.Year() == 2015; day = day.AddDate(0, 0, 7) {
+		date := data.NewDateComponents(day)
+		prayerTimes, err := calc.NewPrayerTimes(coords, date, params)
+		assert.Nil(t, err)
+		for _, prayer := range []calc.Prayer{calc.FAJR, calc.SUNRISE, calc.DHUHR, calc.ASR, calc.MAGHRIB, calc.ISHA} {
+			observations = append(observations, Observation{Date: date, Prayer: prayer, Time: prayerTimes.TimeForPrayer(prayer)})
+		}
+	}
+	return observations
+}
+
+func TestCalibrateMethod(t *testing.T) {
+	coords, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+	params.Madhab = calc.HANAFI
+	params.Adjustments = calc.PrayerAdjustments{FajrAdj: 3, IshaAdj: -2}
+
+	result, err := NewCalibrator(coords).Calibrate(timetable(t, coords, params))
+	assert.Nil(t, err)
+	assert.Equal(t, calc.NORTH_AMERICA, result.Params.Method)
+	assert.Equal(t, calc.HANAFI, result.Params.Madhab)
+	assert.Equal(t, params.Adjustments, result.Params.Adjustments)
+	assert.Equal(t, time.Duration(0), result.Overall.Max)
+	assert.Equal(t, 53, result.Prayers[calc.FAJR].Count)
+	assert.Equal(t, 53*6, result.Overall.Count)
+}
+
+func TestCalibrateCustom(t *testing.T) {
+	coords, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+	params := calc.NewCalculationParametersBuilder().
+		SetFajrAngle(13.5).
+		SetIshaInterval(80).
+		SetAdjustments(calc.PrayerAdjustments{DhuhrAdj: 5, MaghribAdj: 2}).
+		Build()
+
+	result, err := NewCalibrator(coords).Calibrate(timetable(t, coords, params))
+	assert.Nil(t, err)
+	assert.Equal(t, calc.OTHER, result.Params.Method)
+	assert.Equal(t, 13.5, result.Params.FajrAngle)
+	assert.Equal(t, 80, result.Params.IshaInterval)
+	assert.Equal(t, calc.SHAFI_HANBALI_MALIKI, result.Params.Madhab)
+	assert.Equal(t, 5, result.Params.Adjustments.DhuhrAdj)
+	assert.Equal(t, 2, result.Params.Adjustments.MaghribAdj)
+	assert.True(t, result.Overall.Max <= time.Minute)
+}
+
+func TestCalibrateTwilightAngle(t *testing.T) {
+	// Above 59 degrees the sun does not reach 18 degrees below the horizon in summer, so Fajr and
+	// Isha follow the night portions of the angles.
+	coords, err := util.NewCoordinates(59.9139, 10.7522)
+	assert.Nil(t, err)
+	params := calc.NewCalculationParametersBuilder().
+		SetFajrAngle(18).
+		SetIshaAngle(15.5).
+		SetHighLatitudeRule(calc.TWILIGHT_ANGLE).
+		Build()
+
+	result, err := NewCalibrator(coords).SetMethods().Calibrate(timetable(t, coords, params))
+	assert.Nil(t, err)
+	assert.Equal(t, calc.TWILIGHT_ANGLE, result.Params.HighLatitudeRule)
+	assert.Equal(t, 18.0, result.Params.FajrAngle)
+	assert.Equal(t, 15.5, result.Params.IshaAngle)
+	assert.Equal(t, calc.PrayerAdjustments{}, result.Params.Adjustments)
+	assert.True(t, result.Overall.Max <= time.Minute)
+
+	// Angles between those considered leave errors, which must be those of the parameters found.
+	params.FajrAngle, params.IshaAngle = 17.3, 16.2
+	observations := timetable(t, coords, params)
+	calibrator := NewCalibrator(coords)
+	calendar := newSolarCalendar(coords, observations)
+	custom := calibrator.fitCustom(calendar, observations)
+	refit := calibrator.fit(calendar, custom.params, observations)
+	assert.Equal(t, refit.adjustments, custom.adjustments)
+	assert.Equal(t, refit.errors, custom.errors)
+}
+
+func TestCalibrateErrors(t *testing.T) {
+	coords, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+	calibrator := NewCalibrator(coords)
+
+	_, err = calibrator.Calibrate(nil)
+	assert.NotNil(t, err)
+
+	date := data.NewDateComponents(time.Date(2015, time.July, 12, 0, 0, 0, 0, time.UTC))
+	_, err = calibrator.Calibrate([]Observation{{Date: date, Prayer: calc.JUMUAH, Time: time.Date(2015, time.July, 12, 17, 30, 0, 0, time.UTC)}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,38 @@
+package data
+
+import "math"
+
+// The Julian day number of 1 Muharram 1 AH in the tabular Islamic calendar.
+const hijriEpoch = 1948440
+
+type HijriDate struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// NewHijriDate converts the Gregorian date `d` to the tabular (arithmetic) Islamic calendar. The
+// tabular calendar can differ by a day or two from calendars based on the sighting or calculation
+// of the new moon, such as Umm al-Qura.
+func NewHijriDate(d *DateComponents) *HijriDate {
+	jdn := gregorianToJulianDayNumber(d.Year, d.Month, d.Day)
+
+	year := (30*(jdn-hijriEpoch) + 10646) / 10631
+	month := int(math.Ceil(float64(jdn-29-hijriToJulianDayNumber(year, 1, 1))/29.5)) + 1
+	if month > 12 {
+		month = 12
+	}
+	day := jdn - hijriToJulianDayNumber(year, month, 1) + 1
+	return &HijriDate{Year: year, Month: month, Day: day}
+}
+
+func gregorianToJulianDayNumber(year int, month int, day int) int {
+	a := (14 - month) / 12
+	y := year + 4800 - a
+	m := month + 12*a - 3
+	return day + (153*m+2)/5 + 365*y + y/4 - y/100 + y/400 - 32045
+}
+
+func hijriToJulianDayNumber(year int, month int, day int) int {
+	return day + int(math.Ceil(29.5*float64(month-1))) + (year-1)*354 + (3+11*year)/30 + hijriEpoch - 1
+}
@@ -0,0 +1,27 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHijriDate(t *testing.T) {
+	testCases := []struct {
+		date time.Time
+		want HijriDate
+	}{
+		{time.Date(622, time.July, 19, 0, 0, 0, 0, time.UTC), HijriDate{1, 1, 1}},
+		{time.Date(2015, time.June, 18, 0, 0, 0, 0, time.UTC), HijriDate{1436, 9, 1}},
+		{time.Date(2015, time.July, 17, 0, 0, 0, 0, time.UTC), HijriDate{1436, 9, 30}},
+		{time.Date(2015, time.July, 18, 0, 0, 0, 0, time.UTC), HijriDate{1436, 10, 1}},
+		{time.Date(2023, time.July, 19, 0, 0, 0, 0, time.UTC), HijriDate{1445, 1, 1}},
+		// 1445 is a leap year, in which Dhu al-Hijjah has 30 days.
+		{time.Date(2024, time.July, 7, 0, 0, 0, 0, time.UTC), HijriDate{1445, 12, 30}},
+		{time.Date(2024, time.July, 8, 0, 0, 0, 0, time.UTC), HijriDate{1446, 1, 1}},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, &tc.want, NewHijriDate(NewDateComponents(tc.date)), tc.date.String())
+	}
+}
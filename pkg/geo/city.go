@@ -0,0 +1,275 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+//go:embed cities.csv
+var citiesCSV string
+
+// The mean radius of the Earth in kilometres.
+const earthRadius = 6371.0
+
+type City struct {
+	Name    string
+	Aliases []string
+
+	// ISO 3166-1 alpha-2 country code, e.g. "SA"
+	Country string
+
+	Coords *util.Coordinates
+
+	// Approximate elevation in metres above sea level
+	Elevation float64
+
+	// tz database time zone, e.g. "Asia/Riyadh"
+	TimeZone string
+}
+
+var cities = mustLoadCities(citiesCSV)
+
+// Location loads the time zone of the city.
+func (c *City) Location() (*time.Location, error) {
+	return time.LoadLocation(c.TimeZone)
+}
+
+// DefaultMethod returns the calculation method conventionally used in the country of the city.
+func (c *City) DefaultMethod() calc.CalculationMethod {
+	return DefaultMethod(c.Country)
+}
+
+// Cities returns every city in the dataset, ordered by name. The cities returned by this package
+// are copies, which the caller may modify.
+func Cities() []*City {
+	found := make([]*City, 0, len(cities))
+	for _, c := range cities {
+		found = append(found, c.clone())
+	}
+	return found
+}
+
+// Lookup returns the cities with a name or alias equal to `name`. Case, accents and punctuation
+// are ignored.
+func Lookup(name string) []*City {
+	query := normalize(name)
+	var found []*City
+	for _, c := range cities {
+		for _, n := range c.names() {
+			if normalize(n) == query {
+				found = append(found, c.clone())
+				break
+			}
+		}
+	}
+	return found
+}
+
+// Search returns up to `limit` cities with a name or alias starting with `prefix`, ordered by
+// name. Case, accents and punctuation are ignored. A `limit` of 0 returns every match.
+func Search(prefix string, limit int) []*City {
+	query := normalize(prefix)
+	var found []*City
+	for _, c := range cities {
+		if limit > 0 && len(found) == limit {
+			break
+		}
+		for _, n := range c.names() {
+			if strings.HasPrefix(normalize(n), query) {
+				found = append(found, c.clone())
+				break
+			}
+		}
+	}
+	return found
+}
+
+// FuzzySearch returns up to `limit` cities with a name or alias close to `query`, allowing about
+// one typo for every four characters, ordered from the closest match. A name also matches if it
+// starts with a close match of `query`.
+func FuzzySearch(query string, limit int) []*City {
+	q := []rune(normalize(query))
+	maxDistance := len(q) / 4
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	type match struct {
+		city     *City
+		distance int
+	}
+	var matches []match
+	for _, c := range cities {
+		best := maxDistance + 1
+		for _, n := range c.names() {
+			name := []rune(normalize(n))
+			d := levenshtein(q, name)
+			if len(name) > len(q) {
+				if prefix := levenshtein(q, name[:len(q)]); prefix < d {
+					d = prefix
+				}
+			}
+			if d < best {
+				best = d
+			}
+		}
+		if best <= maxDistance {
+			matches = append(matches, match{c, best})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+	var found []*City
+	for _, m := range matches {
+		if limit > 0 && len(found) == limit {
+			break
+		}
+		found = append(found, m.city.clone())
+	}
+	return found
+}
+
+// Nearest returns the city closest to `coords` and its distance in kilometres.
+func Nearest(coords *util.Coordinates) (*City, float64) {
+	var nearest *City
+	nearestDistance := math.Inf(1)
+	for _, c := range cities {
+		if d := distance(coords, c.Coords); d < nearestDistance {
+			nearest, nearestDistance = c, d
+		}
+	}
+	return nearest.clone(), nearestDistance
+}
+
+// DefaultMethod returns the calculation method conventionally used in `country`, an ISO 3166-1
+// alpha-2 code. See calc.RecommendationForCountry.
+func DefaultMethod(country string) calc.CalculationMethod {
+	return calc.RecommendationForCountry(country).Method
+}
+
+// clone returns a copy of the city that shares no memory with it.
+func (c *City) clone() *City {
+	if c == nil {
+		return nil
+	}
+	cloned := *c
+	cloned.Aliases = append([]string(nil), c.Aliases...)
+	coords := *c.Coords
+	cloned.Coords = &coords
+	return &cloned
+}
+
+func (c *City) names() []string {
+	return append([]string{c.Name}, c.Aliases...)
+}
+
+// distance returns the great circle distance between `a` and `b` in kilometres.
+func distance(a *util.Coordinates, b *util.Coordinates) float64 {
+	lat1, lat2 := util.Radians(a.Latitude), util.Radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := util.Radians(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n", "ß", "ss", "æ", "ae",
+)
+
+// normalize lower cases `s`, removes accents and punctuation, and turns hyphens into spaces.
+func normalize(s string) string {
+	s = accents.Replace(strings.ToLower(strings.TrimSpace(s)))
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ':
+			b.WriteRune(r)
+		case r == '-':
+			b.WriteRune(' ')
+		}
+	}
+	return b.String()
+}
+
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func mustLoadCities(s string) []*City {
+	records, err := csv.NewReader(strings.NewReader(s)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("geo: reading cities: %v", err))
+	}
+
+	var loaded []*City
+	for i, r := range records[1:] {
+		latitude, latErr := strconv.ParseFloat(r[3], 64)
+		longitude, lonErr := strconv.ParseFloat(r[4], 64)
+		elevation, elevErr := strconv.ParseFloat(r[5], 64)
+		if latErr != nil || lonErr != nil || elevErr != nil {
+			panic(fmt.Sprintf("geo: city %d: invalid number", i+2))
+		}
+		coords, err := util.NewCoordinates(latitude, longitude)
+		if err != nil {
+			panic(fmt.Sprintf("geo: city %d: %v", i+2, err))
+		}
+
+		var aliases []string
+		if r[1] != "" {
+			aliases = strings.Split(r[1], "|")
+		}
+		loaded = append(loaded, &City{
+			Name:      r[0],
+			Aliases:   aliases,
+			Country:   r[2],
+			Coords:    coords,
+			Elevation: elevation,
+			TimeZone:  r[6],
+		})
+	}
+
+	sort.SliceStable(loaded, func(i, j int) bool {
+		return normalize(loaded[i].Name) < normalize(loaded[j].Name)
+	})
+	return loaded
+}
@@ -0,0 +1,101 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+func names(cities []*City) []string {
+	var n []string
+	for _, c := range cities {
+		n = append(n, c.Name)
+	}
+	return n
+}
+
+func TestCities(t *testing.T) {
+	for _, c := range Cities() {
+		_, err := c.Location()
+		assert.Nil(t, err, c.Name)
+		assert.Len(t, c.Country, 2, c.Name)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	assert.Equal(t, []string{"Mecca"}, names(Lookup("makkah")))
+	assert.Equal(t, []string{"Mecca"}, names(Lookup("Makkah al-Mukarramah")))
+	assert.Equal(t, []string{"Tromsø"}, names(Lookup("TROMSO")))
+	assert.Equal(t, []string{"Montréal"}, names(Lookup("Montreal")))
+	assert.Empty(t, Lookup("Atlantis"))
+}
+
+func TestSearch(t *testing.T) {
+	found := names(Search("ka", 0))
+	assert.Contains(t, found, "Kabul")
+	assert.Contains(t, found, "Karachi")
+	assert.Contains(t, found, "Kazan")
+	assert.NotContains(t, found, "Mecca")
+
+	assert.Len(t, Search("ka", 2), 2)
+	assert.Equal(t, "Karachi", Search("kara", 1)[0].Name)
+}
+
+func TestFuzzySearch(t *testing.T) {
+	assert.Equal(t, "Karachi", FuzzySearch("Karachy", 5)[0].Name)
+	assert.Equal(t, "Mecca", FuzzySearch("Mekka", 5)[0].Name)
+	assert.Equal(t, "Raleigh", FuzzySearch("Raleig", 1)[0].Name)
+	assert.Empty(t, FuzzySearch("Xyzzyq", 5))
+}
+
+func TestNearest(t *testing.T) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	city, km := Nearest(coords)
+	assert.Equal(t, "Raleigh", city.Name)
+	assert.Less(t, km, 1.0)
+
+	coords, _ = util.NewCoordinates(21.4, 39.9)
+	city, km = Nearest(coords)
+	assert.Equal(t, "Mecca", city.Name)
+	assert.InDelta(t, 8.0, km, 1.0)
+}
+
+func TestCitiesAreCopies(t *testing.T) {
+	mecca := Lookup("Mecca")[0]
+	latitude := mecca.Coords.Latitude
+	aliases := append([]string(nil), mecca.Aliases...)
+	assert.NotEmpty(t, aliases)
+
+	for _, c := range []*City{mecca, Search("Mecc", 1)[0], FuzzySearch("Meca", 1)[0], Cities()[indexOf("Mecca")]} {
+		c.Name = "Changed"
+		c.Aliases[0] = "Changed"
+		c.Coords.Latitude = 0
+	}
+	coords, _ := util.NewCoordinates(21.4, 39.9)
+	nearest, _ := Nearest(coords)
+	nearest.Coords.Latitude = 0
+
+	mecca = Lookup("Mecca")[0]
+	assert.Equal(t, "Mecca", mecca.Name)
+	assert.Equal(t, aliases, mecca.Aliases)
+	assert.Equal(t, latitude, mecca.Coords.Latitude)
+}
+
+func indexOf(name string) int {
+	for i, c := range Cities() {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestDefaultMethod(t *testing.T) {
+	assert.Equal(t, calc.UMM_AL_QURA, DefaultMethod("sa"))
+	assert.Equal(t, calc.KARACHI, DefaultMethod("PK"))
+	assert.Equal(t, calc.MUSLIM_WORLD_LEAGUE, DefaultMethod("ZZ"))
+	assert.Equal(t, calc.MOON_SIGHTING_COMMITTEE, Lookup("Raleigh")[0].DefaultMethod())
+}
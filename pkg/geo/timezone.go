@@ -0,0 +1,87 @@
+package geo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	geojson "github.com/mnadev/adhango/internal/geojson"
+	calc "github.com/mnadev/adhango/pkg/calc"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// Simplified boundaries of time zones as a GeoJSON FeatureCollection of MultiPolygons that do not
+// overlap, each with the tz database name of its zone in the "tzid" property.
+//
+//go:embed timezones.json
+var timeZonesJSON []byte
+
+// Distance in kilometres beyond which the time zone of the nearest city is not used.
+const maxNearestCityDistance = 100.0
+
+// The time zone at the coordinates is not known, being outside of the embedded boundaries and far
+// from every city in the dataset.
+var ErrUnknownTimeZone = errors.New("unknown time zone")
+
+type timeZone struct {
+	name  string
+	rings geojson.Rings
+}
+
+var timeZones = mustLoadTimeZones(timeZonesJSON)
+
+// TimeZone returns the tz database time zone at `coords`, e.g. "America/New_York". It uses the
+// embedded boundaries, simplified to a few kilometres, and falls back to the time zone of the
+// nearest city within 100 km where there are none. It returns "" if the time zone is unknown.
+func TimeZone(coords *util.Coordinates) string {
+	for i := range timeZones {
+		if timeZones[i].rings.Contains(coords.Latitude, coords.Longitude) {
+			return timeZones[i].name
+		}
+	}
+	if city, km := Nearest(coords); city != nil && km <= maxNearestCityDistance {
+		return city.TimeZone
+	}
+	return ""
+}
+
+// Location loads the time zone at `coords`. It returns ErrUnknownTimeZone if the time zone is
+// unknown.
+func Location(coords *util.Coordinates) (*time.Location, error) {
+	name := TimeZone(coords)
+	if name == "" {
+		return nil, fmt.Errorf("%w at %v, %v", ErrUnknownTimeZone, coords.Latitude, coords.Longitude)
+	}
+	return time.LoadLocation(name)
+}
+
+// Localize converts the times of `p` to the time zone at its coordinates. It returns
+// ErrUnknownTimeZone, leaving `p` unchanged, if the time zone is unknown.
+func Localize(p *calc.PrayerTimes) error {
+	name := TimeZone(p.Coords)
+	if name == "" {
+		return fmt.Errorf("%w at %v, %v", ErrUnknownTimeZone, p.Coords.Latitude, p.Coords.Longitude)
+	}
+	return p.SetTimeZone(name)
+}
+
+func mustLoadTimeZones(b []byte) []timeZone {
+	features, err := geojson.ReadMultiPolygons(b)
+	if err != nil {
+		panic(fmt.Sprintf("geo: reading time zones: %v", err))
+	}
+
+	loaded := make([]timeZone, 0, len(features))
+	for i, f := range features {
+		var properties struct {
+			TzID string `json:"tzid"`
+		}
+		if err := json.Unmarshal(f.Properties, &properties); err != nil {
+			panic(fmt.Sprintf("geo: time zone %d: %v", i, err))
+		}
+		loaded = append(loaded, timeZone{name: properties.TzID, rings: f.Rings})
+	}
+	return loaded
+}
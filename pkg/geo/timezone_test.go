@@ -0,0 +1,135 @@
+package geo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+func TestTimeZonesLoad(t *testing.T) {
+	for _, z := range timeZones {
+		_, err := time.LoadLocation(z.name)
+		assert.Nil(t, err, z.name)
+	}
+}
+
+func TestTimeZoneNearBorders(t *testing.T) {
+	for _, test := range []struct {
+		place               string
+		latitude, longitude float64
+		timeZone            string
+	}{
+		{"El Paso", 31.76, -106.49, "America/Denver"},
+		{"Pecos", 31.42, -103.49, "America/Chicago"},
+		{"Gallup", 35.53, -108.74, "America/Denver"},
+		{"Phoenix", 33.45, -112.07, "America/Phoenix"},
+		{"St. George", 37.10, -113.58, "America/Denver"},
+		{"Las Vegas", 36.17, -115.14, "America/Los_Angeles"},
+		{"Gary", 41.59, -87.35, "America/Chicago"},
+		{"South Bend", 41.68, -86.25, "America/New_York"},
+		{"Huntsville", 34.73, -86.59, "America/Chicago"},
+		{"Chattanooga", 35.05, -85.31, "America/New_York"},
+		{"Elvas", 38.88, -7.16, "Europe/Lisbon"},
+		{"Badajoz", 38.88, -6.97, "Europe/Madrid"},
+		{"Perpignan", 42.70, 2.89, "Europe/Paris"},
+		{"Figueres", 42.27, 2.96, "Europe/Madrid"},
+		{"Derry", 55.00, -7.32, "Europe/London"},
+		{"Letterkenny", 54.95, -7.73, "Europe/Dublin"},
+		{"Lahore", 31.55, 74.34, "Asia/Karachi"},
+		{"Amritsar", 31.63, 74.87, "Asia/Kolkata"},
+		{"Peshawar", 34.01, 71.58, "Asia/Karachi"},
+		{"Jalalabad", 34.43, 70.45, "Asia/Kabul"},
+		{"Kolkata", 22.57, 88.36, "Asia/Kolkata"},
+		{"Jessore", 23.17, 89.21, "Asia/Dhaka"},
+		{"Kathmandu", 27.72, 85.32, "Asia/Kathmandu"},
+		{"Thimphu", 27.47, 89.64, "Asia/Thimphu"},
+		{"Anchorage", 61.22, -149.90, "America/Anchorage"},
+		{"Juneau", 58.30, -134.42, "America/Anchorage"},
+		{"Honolulu", 21.31, -157.86, "Pacific/Honolulu"},
+		{"Agartala", 23.83, 91.28, "Asia/Kolkata"},
+		{"Sylhet", 24.90, 91.87, "Asia/Dhaka"},
+		{"Siliguri", 26.73, 88.40, "Asia/Kolkata"},
+		{"Birgunj", 27.01, 84.88, "Asia/Kathmandu"},
+		{"Calais", 50.95, 1.85, "Europe/Paris"},
+		{"Dunkirk", 51.03, 2.38, "Europe/Paris"},
+		{"Irun", 43.34, -1.79, "Europe/Madrid"},
+		{"Biarritz", 43.48, -1.56, "Europe/Paris"},
+		{"Porto", 41.15, -8.61, "Europe/Lisbon"},
+		{"Vigo", 42.24, -8.72, "Europe/Madrid"},
+		{"Tucson", 32.22, -110.97, "America/Phoenix"},
+		{"Albuquerque", 35.08, -106.65, "America/Denver"},
+	} {
+		coords, _ := util.NewCoordinates(test.latitude, test.longitude)
+		assert.Equal(t, test.timeZone, TimeZone(coords), test.place)
+	}
+}
+
+func TestTimeZonesDoNotOverlap(t *testing.T) {
+	// Every tenth of a degree over the contiguous United States, Western Europe and South Asia.
+	for _, box := range [][4]float64{{24, -125, 50, -66}, {36, -10, 59, 10}, {20, 60, 38, 98}} {
+		for latitude := box[0] + 0.05; latitude < box[2]; latitude += 0.1 {
+			for longitude := box[1] + 0.05; longitude < box[3]; longitude += 0.1 {
+				coords := &util.Coordinates{Latitude: latitude, Longitude: longitude}
+				var names []string
+				for i := range timeZones {
+					if timeZones[i].rings.Contains(coords.Latitude, coords.Longitude) {
+						names = append(names, timeZones[i].name)
+					}
+				}
+				if len(names) > 1 {
+					t.Fatalf("%v are at %v", names, coords)
+				}
+			}
+		}
+	}
+}
+
+func TestTimeZoneNearestCity(t *testing.T) {
+	coords, _ := util.NewCoordinates(21.4225, 39.8262)
+	assert.Equal(t, "Asia/Riyadh", TimeZone(coords))
+
+	coords, _ = util.NewCoordinates(-33.9249, 18.4241)
+	loc, err := Location(coords)
+	assert.Nil(t, err)
+	assert.Equal(t, "Africa/Johannesburg", loc.String())
+}
+
+func TestTimeZoneUnknown(t *testing.T) {
+	for _, test := range []struct {
+		place               string
+		latitude, longitude float64
+	}{
+		{"Ciudad Juárez", 31.69, -106.42},
+		{"Prince Rupert", 54.31, -130.32},
+		{"North Atlantic", 30, -40},
+	} {
+		coords, _ := util.NewCoordinates(test.latitude, test.longitude)
+		assert.Equal(t, "", TimeZone(coords), test.place)
+
+		_, err := Location(coords)
+		assert.ErrorIs(t, err, ErrUnknownTimeZone, test.place)
+	}
+
+	coords, _ := util.NewCoordinates(30, -40)
+	date := data.NewDateComponents(time.Date(2015, time.July, 12, 0, 0, 0, 0, time.UTC))
+	p, err := calc.NewPrayerTimes(coords, date, calc.GetMethodParameters(calc.MUSLIM_WORLD_LEAGUE))
+	assert.Nil(t, err)
+	assert.ErrorIs(t, Localize(p), ErrUnknownTimeZone)
+	assert.Equal(t, time.UTC, p.Fajr.Location())
+}
+
+func TestLocalize(t *testing.T) {
+	coords, _ := util.NewCoordinates(35.7750, -78.6336)
+	date := data.NewDateComponents(time.Date(2015, time.July, 12, 0, 0, 0, 0, time.UTC))
+	p, err := calc.NewPrayerTimes(coords, date, calc.GetMethodParameters(calc.NORTH_AMERICA))
+	assert.Nil(t, err)
+
+	assert.Nil(t, Localize(p))
+	assert.Equal(t, "America/New_York", p.Fajr.Location().String())
+	assert.Equal(t, "04:42", p.Fajr.Format("15:04"))
+}
@@ -0,0 +1,241 @@
+package grid
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// Grid is a regular grid of points from South to North and West to East, Step degrees apart.
+// Rows run from south to north and columns from west to east. A Grid does not cross the
+// antimeridian.
+type Grid struct {
+	South float64
+	West  float64
+	North float64
+	East  float64
+	Step  float64
+
+	Rows    int
+	Columns int
+}
+
+// NewGrid creates a Grid covering the box from `south`, `west` to `north`, `east`. The last row
+// and column are the last ones `step` apart that fit in the box.
+func NewGrid(south float64, west float64, north float64, east float64, step float64) (*Grid, error) {
+	if !(step > 0) {
+		return nil, fmt.Errorf("%w: step must be greater than 0, got %v", calc.ErrInvalidParameters, step)
+	}
+	if !(south >= -90 && south <= north && north <= 90) {
+		return nil, fmt.Errorf("%w: latitudes must be ordered within -90 to 90, got %v to %v", calc.ErrInvalidParameters, south, north)
+	}
+	if !(west >= -180 && west <= east && east <= 180) {
+		return nil, fmt.Errorf("%w: longitudes must be ordered within -180 to 180, got %v to %v", calc.ErrInvalidParameters, west, east)
+	}
+
+	// Allow for the rounding of the step, so that e.g. 0.1 degree steps reach an edge of 1 degree.
+	const tolerance = 1e-9
+	return &Grid{
+		South:   south,
+		West:    west,
+		North:   north,
+		East:    east,
+		Step:    step,
+		Rows:    int(math.Floor((north-south)/step+tolerance)) + 1,
+		Columns: int(math.Floor((east-west)/step+tolerance)) + 1,
+	}, nil
+}
+
+// Point returns the coordinates of the point at `row`, `column`.
+func (g *Grid) Point(row int, column int) util.Coordinates {
+	return util.Coordinates{
+		Latitude:  g.South + float64(row)*g.Step,
+		Longitude: g.West + float64(column)*g.Step,
+	}
+}
+
+// Points returns every point of the grid, row by row from the south-west corner.
+func (g *Grid) Points() []util.Coordinates {
+	points := make([]util.Coordinates, 0, g.Rows*g.Columns)
+	for row := 0; row < g.Rows; row++ {
+		for column := 0; column < g.Columns; column++ {
+			points = append(points, g.Point(row, column))
+		}
+	}
+	return points
+}
+
+// Evaluator calculates the time of one prayer at many points, spreading the points across
+// goroutines. It is safe for concurrent use.
+type Evaluator struct {
+	params  calc.CalculationParameters
+	prayer  calc.Prayer
+	workers int
+}
+
+// Result is the time of the prayer at a point, or the error calculating it, e.g. near the poles
+// on a day the sun does not rise or set.
+type Result struct {
+	Coords util.Coordinates
+	Time   time.Time
+	Err    error
+}
+
+// NewEvaluator creates an Evaluator of the time of `prayer` calculated with `params`, using one
+// worker per CPU.
+func NewEvaluator(params calc.CalculationParameters, prayer calc.Prayer) *Evaluator {
+	return &Evaluator{
+		params:  params,
+		prayer:  prayer,
+		workers: runtime.GOMAXPROCS(0),
+	}
+}
+
+// SetWorkers sets the number of goroutines calculating times. Values below 1 are treated as 1.
+func (e *Evaluator) SetWorkers(workers int) *Evaluator {
+	if workers < 1 {
+		workers = 1
+	}
+	e.workers = workers
+	return e
+}
+
+// Points calculates the time of the prayer of `date` at each of `points`, as given by
+// calc.CalculatePrayerTimes. Results are in the order of `points`. An error is only returned for
+// an invalid prayer, including JUMUAH; errors of single points are in their Result.
+func (e *Evaluator) Points(date data.DateComponents, points []util.Coordinates) ([]Result, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	results := make([]Result, len(points))
+	e.parallel(len(points), func(i int) {
+		prayerTimes, err := calc.CalculatePrayerTimes(points[i], date, e.params)
+		results[i] = Result{Coords: points[i], Err: err}
+		if err == nil {
+			results[i].Time = prayerTimes.TimeForPrayer(e.prayer)
+		}
+	})
+	return results, nil
+}
+
+// Raster calculates the time of the prayer of `date` at every point of `grid`.
+func (e *Evaluator) Raster(date data.DateComponents, grid *Grid) (*Raster, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	if grid == nil {
+		return nil, fmt.Errorf("%w: grid must not be nil", calc.ErrInvalidParameters)
+	}
+
+	midnight := time.Date(date.Year, time.Month(date.Month), date.Day, 0, 0, 0, 0, time.UTC)
+	points := grid.Points()
+	minutes := make([]float64, len(points))
+	e.parallel(len(points), func(i int) {
+		prayerTimes, err := calc.CalculatePrayerTimes(points[i], date, e.params)
+		if err != nil {
+			minutes[i] = math.NaN()
+			return
+		}
+		// Unrounded, so that isochrones are smooth rather than following the steps of the minutes.
+		details := prayerTimes.Details
+		t := details.TimeForPrayer(e.prayer).Add(time.Duration(details.AdjustmentForPrayer(e.prayer)) * time.Minute)
+		minutes[i] = t.Sub(midnight).Minutes()
+	})
+
+	return &Raster{
+		Grid:    *grid,
+		Date:    date,
+		Prayer:  e.prayer,
+		Minutes: minutes,
+	}, nil
+}
+
+func (e *Evaluator) validate() error {
+	if e.prayer == calc.JUMUAH {
+		return fmt.Errorf("%w: JUMUAH depends on the day of the week, use DHUHR", calc.ErrInvalidParameters)
+	}
+	if e.prayer < calc.FAJR || e.prayer > calc.ISHA {
+		return fmt.Errorf("%w: unknown prayer %d", calc.ErrInvalidParameters, e.prayer)
+	}
+	return nil
+}
+
+// parallel calls `f` with every index below `n`, from up to `workers` goroutines.
+func (e *Evaluator) parallel(n int, f func(i int)) {
+	workers := e.workers
+	if workers > n {
+		workers = n
+	}
+
+	var next int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1) - 1)
+				if i >= n {
+					return
+				}
+				f(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Raster holds the time of a prayer at every point of a Grid.
+type Raster struct {
+	Grid   Grid
+	Date   data.DateComponents
+	Prayer calc.Prayer
+
+	// Minutes from midnight UTC of Date to the prayer, row by row from the south-west corner of
+	// the Grid. Not rounded to the minute, and negative or beyond a day where the prayer falls on
+	// another day in UTC. NaN where the time could not be calculated.
+	Minutes []float64
+}
+
+// At returns the minutes from midnight UTC to the prayer at `row`, `column`, or NaN.
+func (r *Raster) At(row int, column int) float64 {
+	return r.Minutes[row*r.Grid.Columns+column]
+}
+
+// Time returns the time of the prayer at `row`, `column`, or false if it could not be calculated.
+func (r *Raster) Time(row int, column int) (time.Time, bool) {
+	minutes := r.At(row, column)
+	if math.IsNaN(minutes) {
+		return time.Time{}, false
+	}
+	return r.minutesToTime(minutes), true
+}
+
+// Range returns the earliest and latest minutes of the raster, or NaN if there are none.
+func (r *Raster) Range() (float64, float64) {
+	earliest, latest := math.NaN(), math.NaN()
+	for _, minutes := range r.Minutes {
+		if math.IsNaN(minutes) {
+			continue
+		}
+		if math.IsNaN(earliest) || minutes < earliest {
+			earliest = minutes
+		}
+		if math.IsNaN(latest) || minutes > latest {
+			latest = minutes
+		}
+	}
+	return earliest, latest
+}
+
+func (r *Raster) minutesToTime(minutes float64) time.Time {
+	midnight := time.Date(r.Date.Year, time.Month(r.Date.Month), r.Date.Day, 0, 0, 0, 0, time.UTC)
+	return midnight.Add(time.Duration(math.Round(minutes * float64(time.Minute))))
+}
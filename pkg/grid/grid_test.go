@@ -0,0 +1,168 @@
+package grid
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+var july12 = data.DateComponents{Year: 2015, Month: 7, Day: 12}
+
+func TestNewGrid(t *testing.T) {
+	g, err := NewGrid(30, -80, 31, -79, 0.1)
+	assert.Nil(t, err)
+	assert.Equal(t, 11, g.Rows)
+	assert.Equal(t, 11, g.Columns)
+	assert.InDelta(t, 31, g.Point(10, 0).Latitude, 0.000001)
+	assert.InDelta(t, -79, g.Point(0, 10).Longitude, 0.000001)
+	assert.Len(t, g.Points(), 121)
+
+	g, err = NewGrid(30, -80, 30.25, -79, 0.1)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, g.Rows)
+
+	for _, box := range [][5]float64{
+		{30, -80, 31, -79, 0},
+		{31, -80, 30, -79, 0.1},
+		{30, -79, 31, -80, 0.1},
+		{-91, -80, 31, -79, 0.1},
+		{30, -80, 31, 181, 0.1},
+	} {
+		_, err := NewGrid(box[0], box[1], box[2], box[3], box[4])
+		assert.True(t, errors.Is(err, calc.ErrInvalidParameters), box)
+	}
+}
+
+func TestEvaluatorPoints(t *testing.T) {
+	params := calc.MethodParameters(calc.NORTH_AMERICA)
+	points := []util.Coordinates{
+		{Latitude: 35.7750, Longitude: -78.6336},
+		{Latitude: 21.4225, Longitude: 39.8262},
+		{Latitude: 51.5074, Longitude: -0.1278},
+		{Latitude: 78.2232, Longitude: 15.6267},
+	}
+
+	results, err := NewEvaluator(params, calc.ISHA).SetWorkers(3).Points(july12, points)
+	assert.Nil(t, err)
+	assert.Len(t, results, len(points))
+	for i, point := range points {
+		assert.Equal(t, point, results[i].Coords)
+		expected, err := calc.CalculatePrayerTimes(point, july12, params)
+		if err != nil {
+			assert.Equal(t, err, results[i].Err)
+			continue
+		}
+		assert.Nil(t, results[i].Err)
+		assert.Equal(t, expected.Isha, results[i].Time)
+	}
+	// Svalbard has no night in July.
+	assert.NotNil(t, results[3].Err)
+
+	serial, err := NewEvaluator(params, calc.ISHA).SetWorkers(1).Points(july12, points)
+	assert.Nil(t, err)
+	assert.Equal(t, results, serial)
+
+	_, err = NewEvaluator(params, calc.NO_PRAYER).Points(july12, points)
+	assert.True(t, errors.Is(err, calc.ErrInvalidParameters))
+	_, err = NewEvaluator(params, calc.JUMUAH).Points(july12, points)
+	assert.True(t, errors.Is(err, calc.ErrInvalidParameters))
+	g, err := NewGrid(30, -80, 40, -70, 1)
+	assert.Nil(t, err)
+	_, err = NewEvaluator(params, calc.JUMUAH).Raster(july12, g)
+	assert.True(t, errors.Is(err, calc.ErrInvalidParameters))
+}
+
+func TestEvaluatorRaster(t *testing.T) {
+	params := calc.MethodParameters(calc.MUSLIM_WORLD_LEAGUE)
+	g, err := NewGrid(50, 0, 70, 10, 2)
+	assert.Nil(t, err)
+
+	raster, err := NewEvaluator(params, calc.FAJR).Raster(july12, g)
+	assert.Nil(t, err)
+	assert.Len(t, raster.Minutes, g.Rows*g.Columns)
+
+	for row := 0; row < g.Rows; row++ {
+		for column := 0; column < g.Columns; column++ {
+			expected, err := calc.CalculatePrayerTimes(g.Point(row, column), july12, params)
+			actual, ok := raster.Time(row, column)
+			if err != nil {
+				assert.False(t, ok)
+				assert.True(t, math.IsNaN(raster.At(row, column)))
+				continue
+			}
+			assert.True(t, ok)
+			assert.InDelta(t, 0, actual.Sub(expected.Fajr).Seconds(), 30)
+		}
+	}
+
+	// Fajr cannot be calculated in the north, where the sun does not set.
+	_, ok := raster.Time(g.Rows-1, 0)
+	assert.False(t, ok)
+	_, ok = raster.Time(0, 0)
+	assert.True(t, ok)
+
+	_, err = NewEvaluator(params, calc.FAJR).Raster(july12, nil)
+	assert.True(t, errors.Is(err, calc.ErrInvalidParameters))
+}
+
+func TestRasterImage(t *testing.T) {
+	g, err := NewGrid(0, 0, 1, 2, 1)
+	assert.Nil(t, err)
+	raster := &Raster{Grid: *g, Date: july12, Prayer: calc.DHUHR, Minutes: []float64{700, 710, math.NaN(), 720, 730, 740}}
+
+	img := raster.Image()
+	assert.Equal(t, 3, img.Bounds().Dx())
+	assert.Equal(t, 2, img.Bounds().Dy())
+	// North is up, so the first row of the raster is the last of the image.
+	assert.Equal(t, earliestColor, img.NRGBAAt(0, 1))
+	assert.Equal(t, latestColor, img.NRGBAAt(2, 0))
+	assert.Equal(t, uint8(0), img.NRGBAAt(2, 1).A)
+
+	var buf bytes.Buffer
+	assert.Nil(t, raster.WritePNG(&buf))
+	decoded, err := png.Decode(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, img.Bounds(), decoded.Bounds())
+}
+
+func TestRasterTime(t *testing.T) {
+	g, _ := NewGrid(0, 0, 0, 1, 1)
+	raster := &Raster{Grid: *g, Date: july12, Minutes: []float64{-30, 1450.5}}
+
+	earlier, ok := raster.Time(0, 0)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2015, time.July, 11, 23, 30, 0, 0, time.UTC), earlier)
+	later, ok := raster.Time(0, 1)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2015, time.July, 13, 0, 10, 30, 0, time.UTC), later)
+}
+
+func BenchmarkRasterSerial(b *testing.B) {
+	benchmarkRaster(b, 1)
+}
+
+func BenchmarkRasterParallel(b *testing.B) {
+	benchmarkRaster(b, 0)
+}
+
+func benchmarkRaster(b *testing.B, workers int) {
+	g, _ := NewGrid(20, -90, 40, -70, 0.5)
+	e := NewEvaluator(calc.MethodParameters(calc.NORTH_AMERICA), calc.ASR)
+	if workers > 0 {
+		e.SetWorkers(workers)
+	}
+	for i := 0; i < b.N; i++ {
+		if _, err := e.Raster(july12, g); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
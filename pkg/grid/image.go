@@ -0,0 +1,51 @@
+package grid
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Colours of the earliest and latest times of an Image.
+var (
+	earliestColor = color.NRGBA{R: 0x1f, G: 0x2a, B: 0x6b, A: 0xff}
+	latestColor   = color.NRGBA{R: 0xf6, G: 0xd3, B: 0x2d, A: 0xff}
+)
+
+// Image renders the raster with one pixel per point of the grid, north up, shading from dark
+// blue at the earliest time of the raster to yellow at the latest. Points whose time could not be
+// calculated are transparent.
+func (r *Raster) Image() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, r.Grid.Columns, r.Grid.Rows))
+	earliest, latest := r.Range()
+	for row := 0; row < r.Grid.Rows; row++ {
+		for column := 0; column < r.Grid.Columns; column++ {
+			minutes := r.At(row, column)
+			if math.IsNaN(minutes) {
+				continue
+			}
+			fraction := 0.0
+			if latest > earliest {
+				fraction = (minutes - earliest) / (latest - earliest)
+			}
+			img.SetNRGBA(column, r.Grid.Rows-1-row, color.NRGBA{
+				R: blend(earliestColor.R, latestColor.R, fraction),
+				G: blend(earliestColor.G, latestColor.G, fraction),
+				B: blend(earliestColor.B, latestColor.B, fraction),
+				A: 0xff,
+			})
+		}
+	}
+	return img
+}
+
+// WritePNG writes the Image of the raster to `w` as a PNG.
+func (r *Raster) WritePNG(w io.Writer) error {
+	return png.Encode(w, r.Image())
+}
+
+func blend(from uint8, to uint8, fraction float64) uint8 {
+	return uint8(math.Round(float64(from) + (float64(to)-float64(from))*fraction))
+}
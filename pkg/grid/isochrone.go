@@ -0,0 +1,213 @@
+package grid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// Isochrone is a line joining the points where the prayer is at the same time.
+type Isochrone struct {
+	Time time.Time
+
+	// Lines of the isochrone, as points in order. A line whose first point is its last is closed.
+	// An isochrone breaks into several lines where it leaves the grid or crosses points whose time
+	// could not be calculated.
+	Lines [][]util.Coordinates
+}
+
+// Isochrones traces the isochrones of the raster at every multiple of `interval` since midnight
+// UTC, from the earliest time of the raster to the latest, e.g. every 10 minutes. Isochrones are
+// interpolated linearly between the points of the grid, and are returned from the earliest.
+func (r *Raster) Isochrones(interval time.Duration) ([]Isochrone, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("%w: interval must be greater than 0, got %v", calc.ErrInvalidParameters, interval)
+	}
+	earliest, latest := r.Range()
+	if math.IsNaN(earliest) {
+		return nil, nil
+	}
+
+	step := interval.Minutes()
+	var isochrones []Isochrone
+	for k := math.Ceil(earliest / step); k*step <= latest; k++ {
+		level := k * step
+		lines := r.trace(level)
+		if len(lines) == 0 {
+			continue
+		}
+		isochrones = append(isochrones, Isochrone{Time: r.minutesToTime(level), Lines: lines})
+	}
+	return isochrones, nil
+}
+
+// edge identifies the side of a cell between two neighbouring points of the grid: the one from
+// `row`, `column` to the next column, or to the next row if `vertical`.
+type edge struct {
+	row      int
+	column   int
+	vertical bool
+}
+
+// trace returns the lines at `level` with marching squares. Each cell of four points whose times
+// are all known is crossed by up to two segments, joined into lines through their shared edges.
+func (r *Raster) trace(level float64) [][]util.Coordinates {
+	var segments [][2]edge
+	for row := 0; row+1 < r.Grid.Rows; row++ {
+		for column := 0; column+1 < r.Grid.Columns; column++ {
+			segments = append(segments, r.cellSegments(row, column, level)...)
+		}
+	}
+
+	// Every edge is shared by at most two cells, each with at most one segment ending on it.
+	ends := make(map[edge][]int, 2*len(segments))
+	for i, s := range segments {
+		ends[s[0]] = append(ends[s[0]], i)
+		ends[s[1]] = append(ends[s[1]], i)
+	}
+
+	used := make([]bool, len(segments))
+	follow := func(start edge, first int) []edge {
+		path := []edge{start}
+		at, i := start, first
+		for !used[i] {
+			used[i] = true
+			if segments[i][0] == at {
+				at = segments[i][1]
+			} else {
+				at = segments[i][0]
+			}
+			path = append(path, at)
+			for _, j := range ends[at] {
+				if !used[j] {
+					i = j
+					break
+				}
+			}
+		}
+		return path
+	}
+
+	var lines [][]util.Coordinates
+	// Open lines first, from one of their ends, then the closed ones that remain.
+	for i, s := range segments {
+		if used[i] {
+			continue
+		}
+		for _, end := range s {
+			if len(ends[end]) == 1 {
+				lines = append(lines, r.coordinates(follow(end, i), level))
+				break
+			}
+		}
+	}
+	for i, s := range segments {
+		if !used[i] {
+			lines = append(lines, r.coordinates(follow(s[0], i), level))
+		}
+	}
+	return lines
+}
+
+// cellSegments returns the segments of the isochrone at `level` crossing the cell whose
+// south-west corner is at `row`, `column`, as the edges at their ends.
+func (r *Raster) cellSegments(row int, column int, level float64) [][2]edge {
+	// Corners and sides counterclockwise from the south-west, each side following its corner.
+	corners := [4]float64{r.At(row, column), r.At(row, column+1), r.At(row+1, column+1), r.At(row+1, column)}
+	sides := [4]edge{{row, column, false}, {row, column + 1, true}, {row + 1, column, false}, {row, column, true}}
+
+	var above [4]bool
+	for i, value := range corners {
+		if math.IsNaN(value) {
+			return nil
+		}
+		above[i] = value >= level
+	}
+
+	var crossed []edge
+	for i := range sides {
+		if above[i] != above[(i+1)%4] {
+			crossed = append(crossed, sides[i])
+		}
+	}
+	switch len(crossed) {
+	case 2:
+		return [][2]edge{{crossed[0], crossed[1]}}
+	case 4:
+		// A saddle, with opposite corners on the same side of the level. The middle of the cell
+		// decides whether the south-west and north-east corners are joined or cut off.
+		middle := (corners[0] + corners[1] + corners[2] + corners[3]) / 4
+		if (middle >= level) == above[0] {
+			return [][2]edge{{sides[0], sides[1]}, {sides[2], sides[3]}}
+		}
+		return [][2]edge{{sides[3], sides[0]}, {sides[1], sides[2]}}
+	}
+	return nil
+}
+
+// coordinates returns the points where the isochrone at `level` crosses each of `edges`.
+func (r *Raster) coordinates(edges []edge, level float64) []util.Coordinates {
+	line := make([]util.Coordinates, len(edges))
+	for i, e := range edges {
+		from := r.Grid.Point(e.row, e.column)
+		a, b := r.At(e.row, e.column), 0.0
+		if e.vertical {
+			b = r.At(e.row+1, e.column)
+		} else {
+			b = r.At(e.row, e.column+1)
+		}
+
+		// The level is between a and b, which differ since the edge is crossed.
+		fraction := (level - a) / (b - a)
+		if e.vertical {
+			from.Latitude += fraction * r.Grid.Step
+		} else {
+			from.Longitude += fraction * r.Grid.Step
+		}
+		line[i] = from
+	}
+	return line
+}
+
+// WriteGeoJSON writes `isochrones` as a GeoJSON FeatureCollection with a MultiLineString Feature
+// for each, with the time and the name of `prayer` in its "time" and "prayer" properties.
+func WriteGeoJSON(w io.Writer, prayer calc.Prayer, isochrones []Isochrone) error {
+	type geometry struct {
+		Type        string         `json:"type"`
+		Coordinates [][][2]float64 `json:"coordinates"`
+	}
+	type feature struct {
+		Type       string            `json:"type"`
+		Geometry   geometry          `json:"geometry"`
+		Properties map[string]string `json:"properties"`
+	}
+	collection := struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{Type: "FeatureCollection", Features: []feature{}}
+
+	for _, isochrone := range isochrones {
+		lines := make([][][2]float64, 0, len(isochrone.Lines))
+		for _, line := range isochrone.Lines {
+			positions := make([][2]float64, len(line))
+			for i, point := range line {
+				positions[i] = [2]float64{point.Longitude, point.Latitude}
+			}
+			lines = append(lines, positions)
+		}
+		collection.Features = append(collection.Features, feature{
+			Type:     "Feature",
+			Geometry: geometry{Type: "MultiLineString", Coordinates: lines},
+			Properties: map[string]string{
+				"prayer": prayer.String(),
+				"time":   isochrone.Time.Format(time.RFC3339),
+			},
+		})
+	}
+	return json.NewEncoder(w).Encode(collection)
+}
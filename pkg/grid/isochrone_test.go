@@ -0,0 +1,122 @@
+package grid
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+)
+
+func TestDhuhrIsochrones(t *testing.T) {
+	g, err := NewGrid(20, -90, 40, -70, 0.5)
+	assert.Nil(t, err)
+	raster, err := NewEvaluator(calc.MethodParameters(calc.NORTH_AMERICA), calc.DHUHR).Raster(july12, g)
+	assert.Nil(t, err)
+
+	isochrones, err := raster.Isochrones(10 * time.Minute)
+	assert.Nil(t, err)
+	// 20 degrees of longitude are 80 minutes of the turn of the earth.
+	assert.Len(t, isochrones, 8)
+
+	for i, isochrone := range isochrones {
+		assert.Equal(t, 0, isochrone.Time.Minute()%10)
+		if i > 0 {
+			assert.Equal(t, 10*time.Minute, isochrone.Time.Sub(isochrones[i-1].Time))
+		}
+
+		// Dhuhr is at the same time along a meridian, from the south of the grid to the north.
+		assert.Len(t, isochrone.Lines, 1)
+		line := isochrone.Lines[0]
+		assert.InDelta(t, 20, math.Min(line[0].Latitude, line[len(line)-1].Latitude), 0.000001)
+		assert.InDelta(t, 40, math.Max(line[0].Latitude, line[len(line)-1].Latitude), 0.000001)
+		for _, point := range line {
+			assert.InDelta(t, line[0].Longitude, point.Longitude, 0.05)
+		}
+		if i > 0 {
+			// Later to the west, by a degree every 4 minutes.
+			assert.InDelta(t, -2.5, line[0].Longitude-isochrones[i-1].Lines[0][0].Longitude, 0.05)
+		}
+	}
+}
+
+func TestClosedIsochrone(t *testing.T) {
+	g, err := NewGrid(0, 0, 4, 4, 1)
+	assert.Nil(t, err)
+	raster := &Raster{Grid: *g, Date: july12, Minutes: make([]float64, g.Rows*g.Columns)}
+	for row := 0; row < g.Rows; row++ {
+		for column := 0; column < g.Columns; column++ {
+			raster.Minutes[row*g.Columns+column] = 600.2 + math.Hypot(float64(row-2), float64(column-2))
+		}
+	}
+
+	// Earliest at the centre, so that the isochrones are rings around it.
+	isochrones, err := raster.Isochrones(time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2015, time.July, 12, 10, 1, 0, 0, time.UTC), isochrones[0].Time)
+	assert.Len(t, isochrones[0].Lines, 1)
+	ring := isochrones[0].Lines[0]
+	// Crossing the four sides from the centre, each 0.8 of the way to the next point.
+	assert.Len(t, ring, 5)
+	assert.Equal(t, ring[0], ring[len(ring)-1])
+	for _, point := range ring {
+		assert.InDelta(t, 0.8, math.Hypot(point.Latitude-2, point.Longitude-2), 0.000001)
+	}
+
+	// A point without a time breaks the ring.
+	raster.Minutes[2*g.Columns+3] = math.NaN()
+	isochrones, err = raster.Isochrones(time.Minute)
+	assert.Nil(t, err)
+	assert.Len(t, isochrones[0].Lines, 1)
+	line := isochrones[0].Lines[0]
+	assert.Len(t, line, 3)
+	assert.NotEqual(t, line[0], line[len(line)-1])
+
+	_, err = raster.Isochrones(0)
+	assert.True(t, errors.Is(err, calc.ErrInvalidParameters))
+}
+
+func TestWriteGeoJSON(t *testing.T) {
+	g, err := NewGrid(30, -80, 32, -78, 0.25)
+	assert.Nil(t, err)
+	raster, err := NewEvaluator(calc.MethodParameters(calc.NORTH_AMERICA), calc.MAGHRIB).Raster(july12, g)
+	assert.Nil(t, err)
+	isochrones, err := raster.Isochrones(5 * time.Minute)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, isochrones)
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteGeoJSON(&buf, calc.MAGHRIB, isochrones))
+
+	var collection struct {
+		Type     string
+		Features []struct {
+			Type     string
+			Geometry struct {
+				Type        string
+				Coordinates [][][2]float64
+			}
+			Properties map[string]string
+		}
+	}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &collection))
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	assert.Len(t, collection.Features, len(isochrones))
+
+	feature := collection.Features[0]
+	assert.Equal(t, "MultiLineString", feature.Geometry.Type)
+	assert.Equal(t, "Maghrib", feature.Properties["prayer"])
+	assert.Equal(t, isochrones[0].Time.Format(time.RFC3339), feature.Properties["time"])
+	// Positions are longitude first.
+	position := feature.Geometry.Coordinates[0][0]
+	assert.Equal(t, [2]float64{isochrones[0].Lines[0][0].Longitude, isochrones[0].Lines[0][0].Latitude}, position)
+
+	buf.Reset()
+	assert.Nil(t, WriteGeoJSON(&buf, calc.MAGHRIB, nil))
+	assert.JSONEq(t, `{"type":"FeatureCollection","features":[]}`, buf.String())
+}
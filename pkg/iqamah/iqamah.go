@@ -0,0 +1,88 @@
+package iqamah
+
+import (
+	"time"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+)
+
+// IqamahTimes holds the iqamah times for a day. Prayers without an applicable rule are zero.
+type IqamahTimes struct {
+	Fajr    time.Time
+	Dhuhr   time.Time
+	Asr     time.Time
+	Maghrib time.Time
+	Isha    time.Time
+
+	// Set instead of Dhuhr on Fridays when the rule set has an applicable Jumu'ah rule or session.
+	Jumuah time.Time
+
+	// The times of each Jumu'ah session of the rule set on Fridays.
+	JumuahSessions []calc.JumuahTimes
+}
+
+// NewIqamahTimes applies `rules` to `p`. Fixed clock times and seasons are interpreted in the time
+// zone of `p`, so SetTimeZone should be called on `p` first. Nil `rules` are treated as an empty
+// RuleSet, so no prayer has an iqamah time.
+func NewIqamahTimes(p *calc.PrayerTimes, rules *RuleSet) *IqamahTimes {
+	if rules == nil {
+		rules = &RuleSet{}
+	}
+	date := data.ResolveTimeByDateComponents(p.DateComponent)
+
+	iqamah := &IqamahTimes{
+		Fajr:    applyFirst(rules.Fajr, date, p.Fajr),
+		Asr:     applyFirst(rules.Asr, date, p.Asr),
+		Maghrib: applyFirst(rules.Maghrib, date, p.Maghrib),
+		Isha:    applyFirst(rules.Isha, date, p.Isha),
+	}
+
+	if p.IsFriday() {
+		sessions := make([]calc.JumuahSession, 0, len(rules.JumuahSessions))
+		for _, session := range rules.JumuahSessions {
+			// Sessions are checked by RuleSet.Validate, so invalid ones are skipped.
+			if components, err := session.components(); err == nil {
+				sessions = append(sessions, components)
+			}
+		}
+		iqamah.JumuahSessions = p.JumuahTimes(sessions)
+
+		iqamah.Jumuah = applyFirst(rules.Jumuah, date, p.Dhuhr)
+		if iqamah.Jumuah.IsZero() && len(iqamah.JumuahSessions) > 0 {
+			iqamah.Jumuah = iqamah.JumuahSessions[0].Iqamah
+		}
+	}
+	if iqamah.Jumuah.IsZero() {
+		iqamah.Dhuhr = applyFirst(rules.Dhuhr, date, p.Dhuhr)
+	}
+	return iqamah
+}
+
+// TimeForPrayer returns the iqamah time of `prayer`.
+func (i *IqamahTimes) TimeForPrayer(prayer calc.Prayer) time.Time {
+	switch prayer {
+	case calc.FAJR:
+		return i.Fajr
+	case calc.DHUHR:
+		return i.Dhuhr
+	case calc.JUMUAH:
+		return i.Jumuah
+	case calc.ASR:
+		return i.Asr
+	case calc.MAGHRIB:
+		return i.Maghrib
+	case calc.ISHA:
+		return i.Isha
+	}
+	return time.Time{}
+}
+
+func applyFirst(rules []Rule, date time.Time, prayerTime time.Time) time.Time {
+	for _, rule := range rules {
+		if rule.appliesOn(date) {
+			return rule.apply(prayerTime)
+		}
+	}
+	return time.Time{}
+}
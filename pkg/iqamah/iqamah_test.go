@@ -0,0 +1,175 @@
+package iqamah
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+func newPrayerTimes(t *testing.T, year int, month int, day int) *calc.PrayerTimes {
+	coords, err := util.NewCoordinates(33.720817, 73.090032)
+	assert.Nil(t, err)
+
+	prayerTimes, err := calc.NewPrayerTimes(coords, data.NewDateComponents(time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)), calc.GetMethodParameters(calc.KARACHI))
+	assert.Nil(t, err)
+	err = prayerTimes.SetTimeZone("Asia/Karachi")
+	assert.Nil(t, err)
+	return prayerTimes
+}
+
+func TestNewIqamahTimes(t *testing.T) {
+	rules := &RuleSet{
+		Fajr:    []Rule{{Offset: 20}},
+		Dhuhr:   []Rule{{Time: "13:30"}},
+		Asr:     []Rule{{Offset: 10, RoundTo: 15}},
+		Maghrib: []Rule{{Offset: 5}},
+		Jumuah:  []Rule{{Time: "13:15"}},
+	}
+
+	// Tuesday
+	prayerTimes := newPrayerTimes(t, 2015, 9, 1)
+	loc := prayerTimes.Dhuhr.Location()
+	iqamah := NewIqamahTimes(prayerTimes, rules)
+
+	assert.Equal(t, prayerTimes.Fajr.Add(20*time.Minute), iqamah.Fajr)
+	assert.Equal(t, time.Date(2015, time.Month(9), 1, 13, 30, 0, 0, loc), iqamah.Dhuhr)
+	assert.Equal(t, 0, iqamah.Asr.Minute()%15)
+	assert.False(t, iqamah.Asr.Before(prayerTimes.Asr.Add(10*time.Minute)))
+	assert.True(t, iqamah.Asr.Before(prayerTimes.Asr.Add(25*time.Minute)))
+	assert.Equal(t, prayerTimes.Maghrib.Add(5*time.Minute), iqamah.Maghrib)
+	assert.True(t, iqamah.Isha.IsZero())
+	assert.True(t, iqamah.Jumuah.IsZero())
+
+	// Friday
+	prayerTimes = newPrayerTimes(t, 2015, 9, 4)
+	iqamah = NewIqamahTimes(prayerTimes, rules)
+	assert.True(t, iqamah.Dhuhr.IsZero())
+	assert.Equal(t, time.Date(2015, time.Month(9), 4, 13, 15, 0, 0, loc), iqamah.Jumuah)
+	assert.Equal(t, iqamah.Jumuah, iqamah.TimeForPrayer(calc.JUMUAH))
+	assert.True(t, iqamah.TimeForPrayer(calc.DHUHR).IsZero())
+}
+
+func TestJumuahSessions(t *testing.T) {
+	rules := &RuleSet{
+		Dhuhr: []Rule{{Offset: 10}},
+		JumuahSessions: []JumuahSessionRule{
+			{Khutbah: "12:45", Iqamah: "13:10"},
+			{Khutbah: "14:00", Iqamah: "14:20"},
+		},
+	}
+
+	prayerTimes := newPrayerTimes(t, 2015, 9, 4)
+	loc := prayerTimes.Dhuhr.Location()
+	iqamah := NewIqamahTimes(prayerTimes, rules)
+	assert.Equal(t, []calc.JumuahTimes{
+		{Khutbah: time.Date(2015, 9, 4, 12, 45, 0, 0, loc), Iqamah: time.Date(2015, 9, 4, 13, 10, 0, 0, loc)},
+		{Khutbah: time.Date(2015, 9, 4, 14, 0, 0, 0, loc), Iqamah: time.Date(2015, 9, 4, 14, 20, 0, 0, loc)},
+	}, iqamah.JumuahSessions)
+	assert.Equal(t, time.Date(2015, 9, 4, 13, 10, 0, 0, loc), iqamah.Jumuah)
+	assert.True(t, iqamah.Dhuhr.IsZero())
+
+	prayerTimes = newPrayerTimes(t, 2015, 9, 3)
+	iqamah = NewIqamahTimes(prayerTimes, rules)
+	assert.Nil(t, iqamah.JumuahSessions)
+	assert.Equal(t, prayerTimes.Dhuhr.Add(10*time.Minute), iqamah.Dhuhr)
+}
+
+func TestNilRuleSet(t *testing.T) {
+	for _, day := range []int{3, 4} {
+		iqamah := NewIqamahTimes(newPrayerTimes(t, 2015, 9, day), nil)
+		for _, prayer := range []calc.Prayer{calc.FAJR, calc.DHUHR, calc.JUMUAH, calc.ASR, calc.MAGHRIB, calc.ISHA} {
+			assert.True(t, iqamah.TimeForPrayer(prayer).IsZero(), prayer)
+		}
+		assert.Empty(t, iqamah.JumuahSessions)
+	}
+}
+
+func TestFixedTimeNeverBeforePrayer(t *testing.T) {
+	prayerTimes := newPrayerTimes(t, 2015, 9, 1)
+	iqamah := NewIqamahTimes(prayerTimes, &RuleSet{Dhuhr: []Rule{{Time: "11:00"}}})
+	assert.Equal(t, prayerTimes.Dhuhr, iqamah.Dhuhr)
+}
+
+func TestSeasonalRules(t *testing.T) {
+	rules := &RuleSet{
+		Isha: []Rule{
+			{Time: "20:30", From: "11-01", To: "02-28"},
+			{Offset: 15},
+		},
+	}
+
+	prayerTimes := newPrayerTimes(t, 2015, 9, 1)
+	iqamah := NewIqamahTimes(prayerTimes, rules)
+	assert.Equal(t, prayerTimes.Isha.Add(15*time.Minute), iqamah.Isha)
+
+	prayerTimes = newPrayerTimes(t, 2015, 12, 1)
+	iqamah = NewIqamahTimes(prayerTimes, rules)
+	assert.Equal(t, time.Date(2015, time.Month(12), 1, 20, 30, 0, 0, prayerTimes.Isha.Location()), iqamah.Isha)
+}
+
+func TestRoundUp(t *testing.T) {
+	loc := time.UTC
+	assert.Equal(t, time.Date(2015, 9, 1, 13, 15, 0, 0, loc), roundUp(time.Date(2015, 9, 1, 13, 1, 0, 0, loc), 15))
+	assert.Equal(t, time.Date(2015, 9, 1, 13, 15, 0, 0, loc), roundUp(time.Date(2015, 9, 1, 13, 15, 0, 0, loc), 15))
+	assert.Equal(t, time.Date(2015, 9, 1, 13, 30, 0, 0, loc), roundUp(time.Date(2015, 9, 1, 13, 15, 1, 0, loc), 15))
+	assert.Equal(t, time.Date(2015, 9, 2, 0, 0, 0, 0, loc), roundUp(time.Date(2015, 9, 1, 23, 50, 0, 0, loc), 30))
+
+	// Steps that do not divide the hour restart at each hour rather than counting through the day.
+	assert.Equal(t, time.Date(2015, 9, 1, 13, 56, 0, 0, loc), roundUp(time.Date(2015, 9, 1, 13, 50, 0, 0, loc), 7))
+	assert.Equal(t, time.Date(2015, 9, 1, 14, 0, 0, 0, loc), roundUp(time.Date(2015, 9, 1, 13, 57, 0, 0, loc), 7))
+	assert.Equal(t, time.Date(2015, 9, 1, 14, 7, 0, 0, loc), roundUp(time.Date(2015, 9, 1, 14, 0, 1, 0, loc), 7))
+	assert.Equal(t, time.Date(2015, 9, 1, 13, 45, 0, 0, loc), roundUp(time.Date(2015, 9, 1, 13, 1, 0, 0, loc), 45))
+	assert.Equal(t, time.Date(2015, 9, 1, 14, 0, 0, 0, loc), roundUp(time.Date(2015, 9, 1, 13, 50, 0, 0, loc), 45))
+	assert.Equal(t, time.Date(2015, 9, 2, 0, 0, 0, 0, loc), roundUp(time.Date(2015, 9, 1, 23, 46, 0, 0, loc), 45))
+}
+
+func TestFixedTimeAfterMidnight(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	assert.Nil(t, err)
+	// Isha of June 20 falling after midnight, as it can at high latitudes.
+	prayerTimes := &calc.PrayerTimes{
+		DateComponent: &data.DateComponents{Year: 2015, Month: 6, Day: 20},
+		Isha:          time.Date(2015, 6, 21, 0, 40, 0, 0, loc),
+	}
+
+	// A fixed time before midnight is the evening of June 20, before the prayer.
+	iqamah := NewIqamahTimes(prayerTimes, &RuleSet{Isha: []Rule{{Time: "23:30"}}})
+	assert.Equal(t, prayerTimes.Isha, iqamah.Isha)
+
+	iqamah = NewIqamahTimes(prayerTimes, &RuleSet{Isha: []Rule{{Time: "00:50"}}})
+	assert.Equal(t, time.Date(2015, 6, 21, 0, 50, 0, 0, loc), iqamah.Isha)
+
+	// And a fixed time after midnight is the next day for an Isha before midnight.
+	prayerTimes.Isha = time.Date(2015, 6, 20, 23, 50, 0, 0, loc)
+	iqamah = NewIqamahTimes(prayerTimes, &RuleSet{Isha: []Rule{{Time: "00:15"}}})
+	assert.Equal(t, time.Date(2015, 6, 21, 0, 15, 0, 0, loc), iqamah.Isha)
+}
+
+func TestLoadRuleSet(t *testing.T) {
+	rules, err := LoadRuleSet(strings.NewReader(`{
+		"fajr": [{"offset": 20}],
+		"jumuah": [{"time": "13:15"}],
+		"isha": [{"time": "20:30", "from": "11-01", "to": "02-28"}, {"offset": 10, "round_to": 5}]
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, &RuleSet{
+		Fajr:   []Rule{{Offset: 20}},
+		Jumuah: []Rule{{Time: "13:15"}},
+		Isha:   []Rule{{Time: "20:30", From: "11-01", To: "02-28"}, {Offset: 10, RoundTo: 5}},
+	}, rules)
+
+	_, err = LoadRuleSet(strings.NewReader(`{"dhuhr": [{"time": "25:00"}]}`))
+	assert.NotNil(t, err)
+
+	_, err = LoadRuleSet(strings.NewReader(`{"dhuhr": [{"offset": 5, "from": "11-01"}]}`))
+	assert.NotNil(t, err)
+
+	_, err = LoadRuleSet(strings.NewReader(`{"jumuah_sessions": [{"khutbah": "12:45", "iqamah": "1:10 PM"}]}`))
+	assert.NotNil(t, err)
+}
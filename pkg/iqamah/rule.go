@@ -0,0 +1,186 @@
+package iqamah
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+)
+
+// Rule describes how a mosque derives the iqamah time of a prayer from its adhan time.
+type Rule struct {
+	// Minutes after the prayer time. Ignored if Time is set.
+	Offset int `json:"offset,omitempty"`
+
+	// Fixed local clock time in the form "15:04", taken on whichever day puts it within 12 hours of
+	// the prayer time, e.g. the evening before an Isha that falls after midnight. The iqamah is
+	// never earlier than the prayer time, so a fixed time that falls before it is replaced by the
+	// prayer time.
+	Time string `json:"time,omitempty"`
+
+	// If set, the iqamah is rounded up to the next multiple of this many minutes past the hour,
+	// e.g. 15 for the next quarter hour.
+	RoundTo int `json:"round_to,omitempty"`
+
+	// Inclusive range of dates in the form "01-02" (month-day) during which the rule applies. The
+	// range may wrap around the end of the year. If both are empty the rule applies all year.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// RuleSet holds the iqamah rules of a mosque. Each prayer has a list of rules, and the first rule
+// whose season contains the date is used. Prayers without a matching rule have no iqamah time.
+type RuleSet struct {
+	Fajr    []Rule `json:"fajr,omitempty"`
+	Dhuhr   []Rule `json:"dhuhr,omitempty"`
+	Asr     []Rule `json:"asr,omitempty"`
+	Maghrib []Rule `json:"maghrib,omitempty"`
+	Isha    []Rule `json:"isha,omitempty"`
+
+	// Rules used instead of the Dhuhr rules on Fridays.
+	Jumuah []Rule `json:"jumuah,omitempty"`
+
+	// The Friday congregations of the mosque. If there are no Jumuah rules, the iqamah of the first
+	// session is used as the Jumu'ah iqamah.
+	JumuahSessions []JumuahSessionRule `json:"jumuah_sessions,omitempty"`
+}
+
+// JumuahSessionRule is a Friday congregation with khutbah and iqamah given as local clock times in
+// the form "15:04". It is parsed into a calc.JumuahSession.
+type JumuahSessionRule struct {
+	Khutbah string `json:"khutbah"`
+	Iqamah  string `json:"iqamah"`
+}
+
+// LoadRuleSet reads a JSON encoded RuleSet from `r` and validates it.
+func LoadRuleSet(r io.Reader) (*RuleSet, error) {
+	var rs RuleSet
+	if err := json.NewDecoder(r).Decode(&rs); err != nil {
+		return nil, err
+	}
+	if err := rs.Validate(); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// Validate returns an error describing the first malformed rule in the set.
+func (rs *RuleSet) Validate() error {
+	groups := []struct {
+		name  string
+		rules []Rule
+	}{
+		{"fajr", rs.Fajr},
+		{"dhuhr", rs.Dhuhr},
+		{"asr", rs.Asr},
+		{"maghrib", rs.Maghrib},
+		{"isha", rs.Isha},
+		{"jumuah", rs.Jumuah},
+	}
+	for _, g := range groups {
+		for i, rule := range g.rules {
+			if err := rule.validate(); err != nil {
+				return fmt.Errorf("%s rule %d: %w", g.name, i, err)
+			}
+		}
+	}
+	for i, session := range rs.JumuahSessions {
+		if _, err := session.components(); err != nil {
+			return fmt.Errorf("jumuah session %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s *JumuahSessionRule) components() (calc.JumuahSession, error) {
+	khutbah, err := parseClock(s.Khutbah)
+	if err != nil {
+		return calc.JumuahSession{}, err
+	}
+	iqamah, err := parseClock(s.Iqamah)
+	if err != nil {
+		return calc.JumuahSession{}, err
+	}
+	return calc.JumuahSession{Khutbah: khutbah, Iqamah: iqamah}, nil
+}
+
+func parseClock(value string) (data.TimeComponents, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return data.TimeComponents{}, fmt.Errorf("invalid time %q", value)
+	}
+	return data.TimeComponents{Hours: t.Hour(), Minutes: t.Minute()}, nil
+}
+
+func (r *Rule) validate() error {
+	if r.Time != "" {
+		if _, err := parseClock(r.Time); err != nil {
+			return err
+		}
+	}
+	if r.RoundTo < 0 || r.RoundTo > 60 {
+		return fmt.Errorf("round_to must be between 0 and 60 minutes")
+	}
+	if (r.From == "") != (r.To == "") {
+		return fmt.Errorf("from and to must be set together")
+	}
+	for _, d := range []string{r.From, r.To} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("01-02", d); err != nil {
+			return fmt.Errorf("invalid date %q", d)
+		}
+	}
+	return nil
+}
+
+// appliesOn reports whether `date` is within the season of the rule.
+func (r *Rule) appliesOn(date time.Time) bool {
+	if r.From == "" {
+		return true
+	}
+	day := date.Format("01-02")
+	if r.From <= r.To {
+		return r.From <= day && day <= r.To
+	}
+	return day >= r.From || day <= r.To
+}
+
+// apply returns the iqamah time for a prayer beginning at `prayerTime`.
+func (r *Rule) apply(prayerTime time.Time) time.Time {
+	iqamah := prayerTime.Add(time.Minute * time.Duration(r.Offset))
+	if r.Time != "" {
+		clock, _ := parseClock(r.Time)
+		iqamah = time.Date(prayerTime.Year(), prayerTime.Month(), prayerTime.Day(), clock.Hours, clock.Minutes, 0, 0, prayerTime.Location())
+		if iqamah.Sub(prayerTime) > 12*time.Hour {
+			iqamah = iqamah.AddDate(0, 0, -1)
+		} else if prayerTime.Sub(iqamah) > 12*time.Hour {
+			iqamah = iqamah.AddDate(0, 0, 1)
+		}
+		if iqamah.Before(prayerTime) {
+			iqamah = prayerTime
+		}
+	}
+	return roundUp(iqamah, r.RoundTo)
+}
+
+// roundUp rounds `t` up to the next multiple of `minutes` past the hour, in the local time of `t`.
+// When `minutes` does not divide 60, the last multiple in an hour is followed by the next hour.
+func roundUp(t time.Time, minutes int) time.Time {
+	if minutes <= 0 {
+		return t
+	}
+	minute := t.Minute()
+	if t.Second() > 0 || t.Nanosecond() > 0 {
+		minute++
+	}
+	minute = (minute + minutes - 1) / minutes * minutes
+	if minute >= 60 {
+		minute = 60
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), minute, 0, 0, t.Location())
+}
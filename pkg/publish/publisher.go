@@ -0,0 +1,137 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	scheduler "github.com/mnadev/adhango/pkg/scheduler"
+)
+
+// Message is a prayer event encoded for publishing.
+type Message struct {
+	// Hierarchical topic in the style of MQTT, such as "adhango/prayer_time/fajr".
+	Topic string
+
+	// JSON encoded Payload.
+	Payload []byte
+}
+
+// Payload is the JSON body published for every event.
+type Payload struct {
+	Kind          string    `json:"kind"`
+	Prayer        string    `json:"prayer"`
+	PrayerTime    time.Time `json:"prayer_time"`
+	At            time.Time `json:"at"`
+	BeforeSeconds int64     `json:"before_seconds,omitempty"`
+}
+
+// Transport delivers messages to a destination such as a webhook or a message broker.
+type Transport interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// TransportFunc adapts a function to a Transport.
+type TransportFunc func(ctx context.Context, msg Message) error
+
+func (f TransportFunc) Publish(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+// Publisher encodes scheduler events and publishes them to every configured Transport.
+type Publisher struct {
+	transports  []Transport
+	topicPrefix string
+	onError     func(Message, error)
+}
+
+// NewPublisher creates a Publisher for `transports` using the topic prefix "adhango".
+func NewPublisher(transports ...Transport) *Publisher {
+	return &Publisher{
+		transports:  transports,
+		topicPrefix: "adhango",
+	}
+}
+
+func (p *Publisher) SetTopicPrefix(prefix string) *Publisher {
+	p.topicPrefix = prefix
+	return p
+}
+
+// OnError sets a callback for failures when publishing from a Handler, which has no other way of
+// reporting them.
+func (p *Publisher) OnError(f func(Message, error)) *Publisher {
+	p.onError = f
+	return p
+}
+
+// Message encodes `e` as a Message.
+func (p *Publisher) Message(e scheduler.Event) (Message, error) {
+	kind := snakeCase(e.Kind.String())
+	prayer := strings.ToLower(e.Prayer.String())
+	payload, err := json.Marshal(Payload{
+		Kind:          kind,
+		Prayer:        prayer,
+		PrayerTime:    e.PrayerTime,
+		At:            e.At,
+		BeforeSeconds: int64(e.Before / time.Second),
+	})
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Topic:   strings.Join([]string{p.topicPrefix, kind, prayer}, "/"),
+		Payload: payload,
+	}, nil
+}
+
+// Publish sends `e` to every transport. All transports are attempted even if one fails, and the
+// first error is returned.
+func (p *Publisher) Publish(ctx context.Context, e scheduler.Event) error {
+	msg, err := p.Message(e)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, t := range p.transports {
+		err := t.Publish(ctx, msg)
+		if err == nil {
+			continue
+		}
+		if p.onError != nil {
+			p.onError(msg, err)
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("publishing %s: %w", msg.Topic, err)
+		}
+	}
+	return firstErr
+}
+
+// Handler returns a scheduler.Handler that publishes every event using `ctx`. Errors are reported
+// to the OnError callback. The Scheduler waits for the handler to return, so transports should
+// bound their retries.
+func (p *Publisher) Handler(ctx context.Context) scheduler.Handler {
+	return func(e scheduler.Event) {
+		_ = p.Publish(ctx, e)
+	}
+}
+
+// snakeCase converts a CamelCase name such as "PreAlert" to "pre_alert".
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	scheduler "github.com/mnadev/adhango/pkg/scheduler"
+)
+
+func TestPublisherMessage(t *testing.T) {
+	prayerTime := time.Date(2015, time.Month(9), 1, 4, 30, 0, 0, time.UTC)
+	msg, err := NewPublisher().SetTopicPrefix("mosque/1").Message(scheduler.Event{
+		Kind:       scheduler.PRE_ALERT,
+		Prayer:     calc.FAJR,
+		PrayerTime: prayerTime,
+		At:         prayerTime.Add(-10 * time.Minute),
+		Before:     10 * time.Minute,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "mosque/1/pre_alert/fajr", msg.Topic)
+
+	var payload Payload
+	err = json.Unmarshal(msg.Payload, &payload)
+	assert.Nil(t, err)
+	assert.Equal(t, Payload{
+		Kind:          "pre_alert",
+		Prayer:        "fajr",
+		PrayerTime:    prayerTime,
+		At:            prayerTime.Add(-10 * time.Minute),
+		BeforeSeconds: 600,
+	}, payload)
+}
+
+func TestPublisherPublishesToAllTransports(t *testing.T) {
+	failure := errors.New("broker unavailable")
+	var delivered []Message
+	var failed []Message
+
+	p := NewPublisher(
+		TransportFunc(func(ctx context.Context, msg Message) error {
+			return failure
+		}),
+		TransportFunc(func(ctx context.Context, msg Message) error {
+			delivered = append(delivered, msg)
+			return nil
+		}),
+	).OnError(func(msg Message, err error) {
+		failed = append(failed, msg)
+	})
+
+	p.Handler(context.Background())(scheduler.Event{Kind: scheduler.PRAYER_TIME, Prayer: calc.MAGHRIB})
+	assert.Len(t, delivered, 1)
+	assert.Equal(t, "adhango/prayer_time/maghrib", delivered[0].Topic)
+	assert.Len(t, failed, 1)
+
+	err := p.Publish(context.Background(), scheduler.Event{Kind: scheduler.PRAYER_TIME, Prayer: calc.ISHA})
+	assert.True(t, errors.Is(err, failure))
+	assert.Len(t, delivered, 2)
+}
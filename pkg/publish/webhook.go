@@ -0,0 +1,133 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	TopicHeader     = "X-Adhango-Topic"
+	TimestampHeader = "X-Adhango-Timestamp"
+	SignatureHeader = "X-Adhango-Signature"
+)
+
+// StatusError is returned when a webhook responds with a non-2xx status code.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", e.StatusCode)
+}
+
+// WebhookTransport posts messages as JSON to an HTTP endpoint.
+//
+// If a secret is set, each request carries a SignatureHeader of the form "sha256=<hex>", the
+// HMAC-SHA256 of the TimestampHeader value, a ".", and the body. Receivers can check it with
+// VerifySignature.
+type WebhookTransport struct {
+	url        string
+	secret     []byte
+	client     *http.Client
+	maxRetries int
+	backoff    time.Duration
+}
+
+// NewWebhookTransport creates a WebhookTransport for `url` that retries failed requests up to 3
+// times, starting with a 1 second backoff that doubles after every attempt.
+func NewWebhookTransport(url string) *WebhookTransport {
+	return &WebhookTransport{
+		url:        url,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		maxRetries: 3,
+		backoff:    time.Second,
+	}
+}
+
+func (w *WebhookTransport) SetSecret(secret []byte) *WebhookTransport {
+	w.secret = secret
+	return w
+}
+
+func (w *WebhookTransport) SetClient(client *http.Client) *WebhookTransport {
+	w.client = client
+	return w
+}
+
+// SetRetries sets the number of retries after the first attempt, and the backoff before the first
+// retry.
+func (w *WebhookTransport) SetRetries(maxRetries int, backoff time.Duration) *WebhookTransport {
+	w.maxRetries = maxRetries
+	w.backoff = backoff
+	return w
+}
+
+// Publish posts `msg`, retrying on network errors, 429 and 5xx responses.
+func (w *WebhookTransport) Publish(ctx context.Context, msg Message) error {
+	backoff := w.backoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = w.post(ctx, msg)
+		if err == nil || !retry || attempt >= w.maxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends a single request, returning whether a failure is worth retrying.
+func (w *WebhookTransport) post(ctx context.Context, msg Message) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(msg.Payload))
+	if err != nil {
+		return false, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TopicHeader, msg.Topic)
+	req.Header.Set(TimestampHeader, timestamp)
+	if len(w.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(w.secret, timestamp, msg.Payload))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, &StatusError{StatusCode: resp.StatusCode}
+}
+
+// Sign returns the SignatureHeader value for `payload` sent at `timestamp`.
+func Sign(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether `signature` is a valid SignatureHeader value for `payload` sent
+// at `timestamp`.
+func VerifySignature(secret []byte, timestamp string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, payload)), []byte(signature))
+}
@@ -0,0 +1,85 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookTransportSignsRequests(t *testing.T) {
+	secret := []byte("s3cret")
+	msg := Message{Topic: "adhango/prayer_time/fajr", Payload: []byte(`{"prayer":"fajr"}`)}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, msg.Payload, body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, msg.Topic, r.Header.Get(TopicHeader))
+		assert.True(t, VerifySignature(secret, r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader)))
+		assert.False(t, VerifySignature([]byte("wrong"), r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader)))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	err := NewWebhookTransport(server.URL).SetSecret(secret).Publish(context.Background(), msg)
+	assert.Nil(t, err)
+}
+
+func TestWebhookTransportRetries(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := NewWebhookTransport(server.URL).
+		SetRetries(3, time.Millisecond).
+		Publish(context.Background(), Message{Payload: []byte(`{}`)})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestWebhookTransportDoesNotRetryClientErrors(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := NewWebhookTransport(server.URL).
+		SetRetries(3, time.Millisecond).
+		Publish(context.Background(), Message{Payload: []byte(`{}`)})
+
+	var statusErr *StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestWebhookTransportGivesUp(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := NewWebhookTransport(server.URL).
+		SetRetries(2, time.Millisecond).
+		Publish(context.Background(), Message{Payload: []byte(`{}`)})
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"time"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+)
+
+type EventKind int64
+
+const (
+	// The prayer time has been reached.
+	PRAYER_TIME EventKind = iota
+
+	// The prayer time will be reached after the configured pre-alert duration.
+	PRE_ALERT
+)
+
+type Event struct {
+	Kind   EventKind
+	Prayer calc.Prayer
+
+	// The time at which the prayer begins, in the location of the Scheduler.
+	PrayerTime time.Time
+
+	// The time at which the event is due. This is PrayerTime for PRAYER_TIME events, and
+	// PrayerTime minus Before for PRE_ALERT events.
+	At time.Time
+
+	// How long before the prayer time a PRE_ALERT event is due. Zero for PRAYER_TIME events.
+	Before time.Duration
+}
+
+// Handler is a callback invoked by a Scheduler for every event.
+type Handler func(Event)
+
+func (k EventKind) String() string {
+	switch k {
+	case PRAYER_TIME:
+		return "PrayerTime"
+	case PRE_ALERT:
+		return "PreAlert"
+	}
+	return "Unknown"
+}
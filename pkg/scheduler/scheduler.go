@@ -0,0 +1,234 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// Scheduler emits an Event at each prayer time, and at configurable pre-alerts before them, for a
+// single location.
+//
+// The schedule is recalculated every time the Scheduler wakes up, which happens at least once per
+// resync interval. This picks up changes of day, of location and of the system clock. If the clock
+// jumps forward by more than the resync interval, the events that were skipped over are dropped
+// rather than delivered late.
+type Scheduler struct {
+	mu             sync.Mutex
+	source         calc.TimetableSource
+	loc            *time.Location
+	clock          calc.Clock
+	prayers        []calc.Prayer
+	preAlerts      map[calc.Prayer][]time.Duration
+	handlers       []Handler
+	resyncInterval time.Duration
+}
+
+// NewScheduler creates a Scheduler for `coords` using `params`. By default events are emitted in
+// UTC for Fajr, Dhuhr, Asr, Maghrib and Isha, using the system clock and a resync interval of one
+// minute.
+func NewScheduler(coords *util.Coordinates, params *calc.CalculationParameters) *Scheduler {
+	return NewSchedulerWithSource(calc.NewCalculatedSource(coords, params))
+}
+
+// NewSchedulerWithSource is NewScheduler, taking the prayer times of each day from `source`, e.g.
+// a calc.StaticSource for a mosque that follows its published timetable.
+func NewSchedulerWithSource(source calc.TimetableSource) *Scheduler {
+	return &Scheduler{
+		source:         source,
+		loc:            time.UTC,
+		clock:          calc.NewSystemClock(),
+		prayers:        []calc.Prayer{calc.FAJR, calc.DHUHR, calc.ASR, calc.MAGHRIB, calc.ISHA},
+		preAlerts:      map[calc.Prayer][]time.Duration{},
+		resyncInterval: time.Minute,
+	}
+}
+
+// SetLocation sets the location used to determine the local date and to report event times.
+func (s *Scheduler) SetLocation(loc *time.Location) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loc = loc
+}
+
+// SetTimeZone is SetLocation using the tz database name `tzone`.
+func (s *Scheduler) SetTimeZone(tzone string) error {
+	loc, err := time.LoadLocation(tzone)
+	if err != nil {
+		return err
+	}
+	s.SetLocation(loc)
+	return nil
+}
+
+func (s *Scheduler) SetClock(clock calc.Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+}
+
+// SetPrayers sets which prayers events are emitted for. JUMUAH emits events on Fridays only, and
+// is dropped if DHUHR is set as well, as Dhuhr is reported as JUMUAH on Fridays.
+func (s *Scheduler) SetPrayers(prayers ...calc.Prayer) {
+	hasDhuhr := false
+	for _, prayer := range prayers {
+		if prayer == calc.DHUHR {
+			hasDhuhr = true
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prayers = nil
+	for _, prayer := range prayers {
+		if prayer == calc.JUMUAH && hasDhuhr {
+			continue
+		}
+		s.prayers = append(s.prayers, prayer)
+	}
+}
+
+// AddPreAlert adds an event `before` the time of `prayer`.
+func (s *Scheduler) AddPreAlert(prayer calc.Prayer, before time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.preAlerts[prayer] = append(s.preAlerts[prayer], before)
+}
+
+// OnEvent registers `h` to be called for every event. Handlers are called in order of
+// registration, before the event is sent on the channel passed to Run.
+func (s *Scheduler) OnEvent(h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, h)
+}
+
+// SetResyncInterval sets the longest time the Scheduler sleeps before recalculating its schedule.
+func (s *Scheduler) SetResyncInterval(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resyncInterval = d
+}
+
+// Run emits events until `ctx` is done, at which point it returns nil. Each event is passed to the
+// registered handlers and then sent on `events`, if it is not nil. An error is returned if the
+// prayer times cannot be calculated or today's are missing from the source.
+func (s *Scheduler) Run(ctx context.Context, events chan<- Event) error {
+	last := s.now()
+	for {
+		pending, err := s.eventsAfter(last)
+		if err != nil {
+			return err
+		}
+
+		wait := s.resync()
+		if len(pending) > 0 && pending[0].At.Sub(last) < wait {
+			wait = pending[0].At.Sub(last)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.getClock().After(wait):
+		}
+
+		now := s.now()
+		if !now.Before(last) && now.Sub(last) <= wait+s.resync() {
+			for _, event := range pending {
+				if event.At.After(now) {
+					break
+				}
+				if !s.emit(ctx, events, event) {
+					return nil
+				}
+			}
+		}
+		last = now
+	}
+}
+
+func (s *Scheduler) emit(ctx context.Context, events chan<- Event, event Event) bool {
+	s.mu.Lock()
+	handlers := s.handlers
+	s.mu.Unlock()
+
+	for _, h := range handlers {
+		h(event)
+	}
+
+	if events == nil {
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case events <- event:
+		return true
+	}
+}
+
+// eventsAfter returns the events due after `t`, ordered by the time they are due.
+func (s *Scheduler) eventsAfter(t time.Time) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	local := t.In(s.loc)
+	var events []Event
+	// Isha can fall after midnight and pre-alerts can fall before it, so the surrounding days are
+	// included as well, if the source has them.
+	for offset := -1; offset <= 1; offset++ {
+		date := data.NewDateComponents(time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, time.UTC))
+		prayerTimes, err := s.source.PrayerTimes(date)
+		if offset != 0 && errors.Is(err, calc.ErrNotInTimetable) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, prayer := range s.prayers {
+			prayerTime := prayerTimes.TimeForPrayer(prayer).In(s.loc)
+			// Dhuhr is reported as Jumu'ah on Fridays, using the pre-alerts configured for Dhuhr.
+			reported := prayer
+			if prayer == calc.DHUHR && prayerTimes.IsFriday() {
+				reported = calc.JUMUAH
+			}
+			if prayerTime.After(t) {
+				events = append(events, Event{Kind: PRAYER_TIME, Prayer: reported, PrayerTime: prayerTime, At: prayerTime})
+			}
+			for _, before := range s.preAlerts[prayer] {
+				at := prayerTime.Add(-before)
+				if at.After(t) {
+					events = append(events, Event{Kind: PRE_ALERT, Prayer: reported, PrayerTime: prayerTime, At: at, Before: before})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.Before(events[j].At)
+	})
+	return events, nil
+}
+
+func (s *Scheduler) getClock() calc.Clock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clock
+}
+
+func (s *Scheduler) now() time.Time {
+	return s.getClock().Now()
+}
+
+func (s *Scheduler) resync() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resyncInterval
+}
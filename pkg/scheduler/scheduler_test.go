@@ -0,0 +1,221 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+func newTestScheduler(t *testing.T) (*Scheduler, *calc.PrayerTimes) {
+	coords, err := util.NewCoordinates(33.720817, 73.090032)
+	assert.Nil(t, err)
+	params := calc.GetMethodParameters(calc.KARACHI)
+
+	prayerTimes, err := calc.NewPrayerTimes(coords, data.NewDateComponents(time.Date(2015, time.Month(9), 1, 0, 0, 0, 0, time.UTC)), params)
+	assert.Nil(t, err)
+	err = prayerTimes.SetTimeZone("Asia/Karachi")
+	assert.Nil(t, err)
+
+	s := NewScheduler(coords, params)
+	err = s.SetTimeZone("Asia/Karachi")
+	assert.Nil(t, err)
+	return s, prayerTimes
+}
+
+func startScheduler(s *Scheduler) (chan Event, context.CancelFunc, chan error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan Event)
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx, events)
+	}()
+	return events, cancel, done
+}
+
+func TestSchedulerEmitsPreAlertsAndPrayers(t *testing.T) {
+	s, prayerTimes := newTestScheduler(t)
+	clock := calc.NewFakeClock(prayerTimes.Fajr.Add(-7 * time.Minute))
+	s.SetClock(clock)
+	s.AddPreAlert(calc.FAJR, 5*time.Minute)
+
+	var handled []Event
+	s.OnEvent(func(e Event) {
+		handled = append(handled, e)
+	})
+
+	events, cancel, done := startScheduler(s)
+
+	clock.BlockUntil(1)
+	clock.Advance(2 * time.Minute)
+	event := <-events
+	assert.Equal(t, PRE_ALERT, event.Kind)
+	assert.Equal(t, calc.FAJR, event.Prayer)
+	assert.Equal(t, prayerTimes.Fajr, event.PrayerTime)
+	assert.Equal(t, prayerTimes.Fajr.Add(-5*time.Minute), event.At)
+	assert.Equal(t, 5*time.Minute, event.Before)
+	assert.Equal(t, "Asia/Karachi", event.PrayerTime.Location().String())
+
+	for i := 0; i < 5; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+	}
+	event = <-events
+	assert.Equal(t, PRAYER_TIME, event.Kind)
+	assert.Equal(t, calc.FAJR, event.Prayer)
+	assert.Equal(t, prayerTimes.Fajr, event.At)
+
+	cancel()
+	assert.Nil(t, <-done)
+	assert.Len(t, handled, 2)
+}
+
+func TestSchedulerDropsEventsSkippedByClockJump(t *testing.T) {
+	s, prayerTimes := newTestScheduler(t)
+	clock := calc.NewFakeClock(prayerTimes.Fajr.Add(-2 * time.Minute))
+	s.SetClock(clock)
+
+	events, cancel, done := startScheduler(s)
+
+	clock.BlockUntil(1)
+	clock.Set(prayerTimes.Dhuhr.Add(-90 * time.Second))
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+
+	event := <-events
+	assert.Equal(t, PRAYER_TIME, event.Kind)
+	assert.Equal(t, calc.DHUHR, event.Prayer)
+
+	cancel()
+	assert.Nil(t, <-done)
+}
+
+func TestSchedulerRollsOverToNextDay(t *testing.T) {
+	s, prayerTimes := newTestScheduler(t)
+	clock := calc.NewFakeClock(prayerTimes.Isha.Add(-time.Minute))
+	s.SetClock(clock)
+	s.SetResyncInterval(24 * time.Hour)
+
+	events, cancel, done := startScheduler(s)
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	event := <-events
+	assert.Equal(t, calc.ISHA, event.Prayer)
+
+	tomorrow, err := calc.NewPrayerTimes(prayerTimes.Coords, data.NewDateComponents(time.Date(2015, time.Month(9), 2, 0, 0, 0, 0, time.UTC)), prayerTimes.CalculationParams)
+	assert.Nil(t, err)
+
+	clock.BlockUntil(1)
+	clock.Set(tomorrow.Fajr)
+	event = <-events
+	assert.Equal(t, calc.FAJR, event.Prayer)
+	assert.True(t, tomorrow.Fajr.Equal(event.PrayerTime))
+
+	cancel()
+	assert.Nil(t, <-done)
+}
+
+func TestSchedulerReportsJumuahOnFridays(t *testing.T) {
+	s, prayerTimes := newTestScheduler(t)
+	friday, err := calc.NewPrayerTimes(prayerTimes.Coords, data.NewDateComponents(time.Date(2015, time.Month(9), 4, 0, 0, 0, 0, time.UTC)), prayerTimes.CalculationParams)
+	assert.Nil(t, err)
+
+	clock := calc.NewFakeClock(friday.Dhuhr.Add(-time.Minute))
+	s.SetClock(clock)
+
+	events, cancel, done := startScheduler(s)
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	event := <-events
+	assert.Equal(t, calc.JUMUAH, event.Prayer)
+	assert.True(t, friday.Dhuhr.Equal(event.PrayerTime))
+
+	cancel()
+	assert.Nil(t, <-done)
+}
+
+func TestSchedulerDropsJumuahWithDhuhr(t *testing.T) {
+	s, prayerTimes := newTestScheduler(t)
+	friday, err := calc.NewPrayerTimes(prayerTimes.Coords, data.NewDateComponents(time.Date(2015, time.Month(9), 4, 0, 0, 0, 0, time.UTC)), prayerTimes.CalculationParams)
+	assert.Nil(t, err)
+
+	s.SetPrayers(calc.DHUHR, calc.JUMUAH, calc.ASR)
+	clock := calc.NewFakeClock(friday.Dhuhr.Add(-time.Minute))
+	s.SetClock(clock)
+
+	events, cancel, done := startScheduler(s)
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	event := <-events
+	assert.Equal(t, calc.JUMUAH, event.Prayer)
+	assert.True(t, friday.Dhuhr.Equal(event.PrayerTime))
+
+	cancel()
+	assert.Nil(t, <-done)
+
+	// The event for Dhuhr is followed by Asr, not by a second one at the same time.
+	pending, err := s.eventsAfter(friday.Dhuhr.Add(-time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, calc.JUMUAH, pending[0].Prayer)
+	assert.Equal(t, calc.ASR, pending[1].Prayer)
+
+	// JUMUAH alone emits events on Fridays only.
+	s, _ = newTestScheduler(t)
+	s.SetPrayers(calc.JUMUAH)
+	pending, err = s.eventsAfter(friday.Dhuhr.Add(-24 * time.Hour))
+	assert.Nil(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, calc.JUMUAH, pending[0].Prayer)
+}
+
+func TestSchedulerWithStaticSource(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Karachi")
+	assert.Nil(t, err)
+	var days []*calc.PrayerTimes
+	for day := 1; day <= 3; day++ {
+		at := func(hour int, minute int) time.Time {
+			return time.Date(2015, time.September, day, hour, minute, 0, 0, loc)
+		}
+		days = append(days, &calc.PrayerTimes{
+			Fajr: at(4, 30), Sunrise: at(5, 50), Dhuhr: at(12, 30), Asr: at(16, 45), Maghrib: at(18, 45), Isha: at(20, 15),
+			DateComponent: &data.DateComponents{Year: 2015, Month: 9, Day: day},
+		})
+	}
+
+	// The first and last days of the timetable, whose neighbours are missing from it.
+	for _, day := range []int{1, 3} {
+		s := NewSchedulerWithSource(calc.NewStaticSource(days))
+		s.SetLocation(loc)
+		clock := calc.NewFakeClock(time.Date(2015, time.September, day, 12, 29, 0, 0, loc))
+		s.SetClock(clock)
+
+		events, cancel, done := startScheduler(s)
+
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		event := <-events
+		assert.Equal(t, calc.DHUHR, event.Prayer)
+		assert.Equal(t, time.Date(2015, time.September, day, 12, 30, 0, 0, loc), event.PrayerTime)
+
+		cancel()
+		assert.Nil(t, <-done)
+	}
+
+	// A day missing from the timetable is an error.
+	s := NewSchedulerWithSource(calc.NewStaticSource(days))
+	s.SetLocation(loc)
+	s.SetClock(calc.NewFakeClock(time.Date(2015, time.September, 5, 12, 0, 0, 0, loc)))
+	err = s.Run(context.Background(), nil)
+	assert.ErrorIs(t, err, calc.ErrNotInTimetable)
+}
@@ -0,0 +1,115 @@
+package timetable
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+// Delta is the difference between the published and calculated time of a prayer. Positive values
+// mean the published time is later.
+type Delta struct {
+	Prayer     calc.Prayer
+	Published  time.Time
+	Calculated time.Time
+	Delta      time.Duration
+}
+
+type DayReport struct {
+	Date *data.DateComponents
+
+	// One for each prayer in the timetable for the day
+	Deltas []Delta
+
+	// True if the absolute value of any delta is above the threshold of the report
+	Flagged bool
+}
+
+type Report struct {
+	Threshold time.Duration
+	Days      []DayReport
+
+	// The largest absolute delta of any prayer on any day
+	MaxDelta time.Duration
+}
+
+// Compare compares `timetable` with the prayer times calculated for the same days with `coords`
+// and `params`. Days with a delta above `threshold` are flagged.
+func Compare(timetable []*calc.PrayerTimes, coords *util.Coordinates, params *calc.CalculationParameters, threshold time.Duration) (*Report, error) {
+	report := &Report{Threshold: threshold}
+	if len(timetable) == 0 {
+		return report, nil
+	}
+
+	dates := make([]*data.DateComponents, len(timetable))
+	for i, published := range timetable {
+		dates[i] = published.DateComponent
+	}
+	calendar := calc.NewSolarCalendarSpanning(coords, dates)
+
+	for _, published := range timetable {
+		calculated, err := calendar.PrayerTimes(published.DateComponent, params)
+		if err != nil {
+			return nil, fmt.Errorf("%04d-%02d-%02d: %w", published.DateComponent.Year, published.DateComponent.Month, published.DateComponent.Day, err)
+		}
+
+		day := DayReport{Date: published.DateComponent}
+		for _, column := range columns {
+			publishedTime := published.TimeForPrayer(column.prayer)
+			if publishedTime.IsZero() {
+				continue
+			}
+			calculatedTime := calculated.TimeForPrayer(column.prayer).In(publishedTime.Location())
+			delta := publishedTime.Sub(calculatedTime)
+			day.Deltas = append(day.Deltas, Delta{Prayer: column.prayer, Published: publishedTime, Calculated: calculatedTime, Delta: delta})
+			if delta.Abs() > threshold {
+				day.Flagged = true
+			}
+			if delta.Abs() > report.MaxDelta {
+				report.MaxDelta = delta.Abs()
+			}
+		}
+		report.Days = append(report.Days, day)
+	}
+	return report, nil
+}
+
+// FlaggedDays returns the days with a delta above the threshold.
+func (r *Report) FlaggedDays() []DayReport {
+	var flagged []DayReport
+	for _, day := range r.Days {
+		if day.Flagged {
+			flagged = append(flagged, day)
+		}
+	}
+	return flagged
+}
+
+// Write writes the flagged days to `w` as text, with one line per day listing the published time
+// of each prayer and its delta, followed by a summary.
+func (r *Report) Write(w io.Writer) error {
+	flagged := r.FlaggedDays()
+	for _, day := range flagged {
+		if _, err := fmt.Fprintf(w, "%04d-%02d-%02d", day.Date.Year, day.Date.Month, day.Date.Day); err != nil {
+			return err
+		}
+		for _, d := range day.Deltas {
+			mark := ""
+			if d.Delta.Abs() > r.Threshold {
+				mark = "!"
+			}
+			if _, err := fmt.Fprintf(w, "  %v %s (%+.0fm)%s", d.Prayer, d.Published.Format(timeLayout), d.Delta.Minutes(), mark); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d of %d days differ by more than %v, largest difference %v\n", len(flagged), len(r.Days), r.Threshold, r.MaxDelta)
+	return err
+}
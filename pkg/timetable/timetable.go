@@ -0,0 +1,131 @@
+package timetable
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	data "github.com/mnadev/adhango/pkg/data"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	timeLayout = "15:04"
+)
+
+var columns = []struct {
+	name   string
+	prayer calc.Prayer
+}{
+	{"fajr", calc.FAJR},
+	{"sunrise", calc.SUNRISE},
+	{"dhuhr", calc.DHUHR},
+	{"asr", calc.ASR},
+	{"maghrib", calc.MAGHRIB},
+	{"isha", calc.ISHA},
+}
+
+// ReadCSV reads a timetable with one day per row. The first row is a header naming the columns,
+// which are "date", in the form 2006-01-02, and any of "fajr", "sunrise", "dhuhr", "asr",
+// "maghrib" and "isha", in the form 15:04. Times are in `loc`.
+//
+// The prayer times of each day are returned in the order of the rows. The times of missing
+// columns and empty cells are the zero time.
+func ReadCSV(r io.Reader, loc *time.Location) ([]*calc.PrayerTimes, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header")
+	}
+
+	index := map[string]int{}
+	for i, name := range records[0] {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := index["date"]; !ok {
+		return nil, fmt.Errorf("missing date column")
+	}
+
+	var days []*calc.PrayerTimes
+	for i, record := range records[1:] {
+		row := map[string]string{}
+		for name, j := range index {
+			row[name] = record[j]
+		}
+		day, err := newDay(row, loc)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// ReadJSON reads a timetable given as an array of objects, one for each day, with the same keys
+// and values as the columns of ReadCSV.
+func ReadJSON(r io.Reader, loc *time.Location) ([]*calc.PrayerTimes, error) {
+	var rows []map[string]string
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, err
+	}
+
+	var days []*calc.PrayerTimes
+	for i, row := range rows {
+		day, err := newDay(row, loc)
+		if err != nil {
+			return nil, fmt.Errorf("day %d: %w", i, err)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func newDay(row map[string]string, loc *time.Location) (*calc.PrayerTimes, error) {
+	date, err := time.ParseInLocation(dateLayout, strings.TrimSpace(row["date"]), loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", row["date"])
+	}
+
+	day := &calc.PrayerTimes{DateComponent: data.NewDateComponents(date)}
+	for _, column := range columns {
+		value := strings.TrimSpace(row[column.name])
+		if value == "" {
+			continue
+		}
+		clock, err := time.Parse(timeLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s time %q", column.name, value)
+		}
+		t := time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		setTimeForPrayer(day, column.prayer, t)
+	}
+
+	// Isha can be after midnight in summer at high latitudes.
+	if !day.Isha.IsZero() && !day.Maghrib.IsZero() && day.Isha.Before(day.Maghrib) {
+		day.Isha = day.Isha.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+func setTimeForPrayer(p *calc.PrayerTimes, prayer calc.Prayer, t time.Time) {
+	switch prayer {
+	case calc.FAJR:
+		p.Fajr = t
+	case calc.SUNRISE:
+		p.Sunrise = t
+	case calc.DHUHR:
+		p.Dhuhr = t
+	case calc.ASR:
+		p.Asr = t
+	case calc.MAGHRIB:
+		p.Maghrib = t
+	case calc.ISHA:
+		p.Isha = t
+	}
+}
@@ -0,0 +1,82 @@
+package timetable
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	calc "github.com/mnadev/adhango/pkg/calc"
+	util "github.com/mnadev/adhango/pkg/util"
+)
+
+const raleighCSV = `Date,Fajr,Sunrise,Dhuhr,Asr,Maghrib,Isha
+2015-07-12,04:42,06:08,13:21,18:22,20:32,21:57
+2015-07-13,04:43,06:09,13:21,18:22,20:32,22:15
+`
+
+func TestReadCSV(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+
+	days, err := ReadCSV(strings.NewReader(raleighCSV), loc)
+	assert.Nil(t, err)
+	assert.Len(t, days, 2)
+	assert.Equal(t, 12, days[0].DateComponent.Day)
+	assert.Equal(t, time.Date(2015, time.July, 12, 4, 42, 0, 0, loc), days[0].Fajr)
+	assert.Equal(t, time.Date(2015, time.July, 13, 22, 15, 0, 0, loc), days[1].Isha)
+
+	days, err = ReadCSV(strings.NewReader("date,dhuhr\n2015-07-12,13:21\n"), loc)
+	assert.Nil(t, err)
+	assert.True(t, days[0].Fajr.IsZero())
+	assert.Equal(t, time.Date(2015, time.July, 12, 13, 21, 0, 0, loc), days[0].Dhuhr)
+
+	_, err = ReadCSV(strings.NewReader("fajr\n04:42\n"), loc)
+	assert.NotNil(t, err)
+	_, err = ReadCSV(strings.NewReader("date,fajr\n2015-07-12,4:42 AM\n"), loc)
+	assert.NotNil(t, err)
+}
+
+func TestReadJSON(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	assert.Nil(t, err)
+
+	days, err := ReadJSON(strings.NewReader(`[{"date": "2015-06-21", "maghrib": "21:22", "isha": "00:30"}]`), loc)
+	assert.Nil(t, err)
+	assert.Len(t, days, 1)
+	assert.Equal(t, time.Date(2015, time.June, 22, 0, 30, 0, 0, loc), days[0].Isha)
+
+	_, err = ReadJSON(strings.NewReader(`[{"date": "21/06/2015"}]`), loc)
+	assert.NotNil(t, err)
+}
+
+func TestCompare(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	coords, err := util.NewCoordinates(35.7750, -78.6336)
+	assert.Nil(t, err)
+	params := calc.GetMethodParameters(calc.NORTH_AMERICA)
+	params.Madhab = calc.HANAFI
+
+	days, err := ReadCSV(strings.NewReader(raleighCSV), loc)
+	assert.Nil(t, err)
+	report, err := Compare(days, coords, params, 5*time.Minute)
+	assert.Nil(t, err)
+
+	assert.Len(t, report.Days, 2)
+	assert.False(t, report.Days[0].Flagged)
+	for _, d := range report.Days[0].Deltas {
+		assert.Equal(t, time.Duration(0), d.Delta, d.Prayer.String())
+	}
+	assert.True(t, report.Days[1].Flagged)
+	assert.Len(t, report.FlaggedDays(), 1)
+	assert.True(t, report.MaxDelta > 15*time.Minute)
+
+	var b bytes.Buffer
+	assert.Nil(t, report.Write(&b))
+	assert.Contains(t, b.String(), "2015-07-13")
+	assert.Contains(t, b.String(), "Isha 22:15 (+")
+	assert.Contains(t, b.String(), "1 of 2 days differ by more than 5m0s")
+}
@@ -8,7 +8,21 @@ const (
 	DOUBLE
 )
 
+// Deprecated: ShadowLengthToFloatMap can be modified by any package, which changes how every
+// afternoon is calculated. Use ShadowLength.Factor instead, which is what the calculations use.
 var ShadowLengthToFloatMap = map[ShadowLength]float64{
 	SINGLE: 1.0,
 	DOUBLE: 2.0,
 }
+
+// Factor returns the length of the shadow as a multiple of the length of the object, or 0 if the
+// shadow length is unknown.
+func (sl ShadowLength) Factor() float64 {
+	switch sl {
+	case SINGLE:
+		return 1.0
+	case DOUBLE:
+		return 2.0
+	}
+	return 0.0
+}
@@ -25,6 +25,13 @@ func NewSolarTime(d *data.DateComponents, c *Coordinates) *SolarTime {
 	solar := NewSolarCoordinates(julianDate)
 	nextSolar := NewSolarCoordinates(julianDate + 1)
 
+	return NewSolarTimeFromSolarCoordinates(prevSolar, solar, nextSolar, c)
+}
+
+// NewSolarTimeFromSolarCoordinates creates the SolarTime of a day from the solar coordinates of
+// the day before, the day itself and the day after. Consecutive days share two of the three, so
+// computing them once for a range of days avoids most of the work of NewSolarTime.
+func NewSolarTimeFromSolarCoordinates(prevSolar *SolarCoordinates, solar *SolarCoordinates, nextSolar *SolarCoordinates, c *Coordinates) *SolarTime {
 	approximateTransit := ApproximateTransit(c.Longitude, solar.ApparentSiderealTime, solar.RightAscension)
 	solarAltitude := -50.0 / 60.0
 
@@ -49,9 +56,15 @@ func (s *SolarTime) HourAngle(angle float64, afterTransit bool) float64 {
 }
 
 func (s *SolarTime) Afternoon(sl ShadowLength) float64 {
+	return s.AfternoonWithShadowFactor(sl.Factor())
+}
+
+// AfternoonWithShadowFactor returns the time after transit at which the shadow of an object is
+// `factor` times its length plus the length of its shadow at transit.
+func (s *SolarTime) AfternoonWithShadowFactor(factor float64) float64 {
 	// TODO (from Swift version) source shadow angle calculation
 	tangent := math.Abs(s.Observer.Latitude - s.Solar.Declination)
-	inverse := ShadowLengthToFloatMap[sl] + math.Tan(Radians(tangent))
+	inverse := factor + math.Tan(Radians(tangent))
 	angle := Degrees(math.Atan(1.0 / inverse))
 
 	return s.HourAngle(angle, true)
@@ -73,3 +73,10 @@ func TestCalendricalDate(t *testing.T) {
 	wantStrDay2 := "16:14"
 	assert.Equal(t, wantStrDay2, gotStrDay2)
 }
+
+func TestShadowLengthFactor(t *testing.T) {
+	assert.Equal(t, 1.0, SINGLE.Factor())
+	assert.Equal(t, 2.0, DOUBLE.Factor())
+	assert.Equal(t, ShadowLengthToFloatMap[ShadowLength(5)], ShadowLength(5).Factor())
+	assert.Equal(t, 0.0, ShadowLength(5).Factor())
+}